@@ -0,0 +1,106 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// AdmissionRule identifies a single rule of the AdmissionPolicy.
+type AdmissionRule string
+
+const (
+	// AdmissionRuleNoForks rejects repositories that are forks of another repository.
+	AdmissionRuleNoForks AdmissionRule = "no-forks"
+	// AdmissionRuleVersionTag rejects repositories without a tag that is a valid semantic version.
+	AdmissionRuleVersionTag AdmissionRule = "version-tag"
+	// AdmissionRuleDescription rejects repositories without a description.
+	AdmissionRuleDescription AdmissionRule = "description"
+)
+
+// AdmissionPolicy describes the requirements a repository must meet to be added as a module. All rules are disabled
+// by default.
+type AdmissionPolicy struct {
+	// RejectForks rejects repositories the VCS system reports as a fork.
+	RejectForks bool
+	// RequireVersionTag rejects repositories that have no tag that is a valid semantic version.
+	RequireVersionTag bool
+	// RequireDescription rejects repositories with an empty description.
+	RequireDescription bool
+}
+
+// AdmissionViolation describes a single admission rule the repository failed.
+type AdmissionViolation struct {
+	Rule    AdmissionRule
+	Message string
+}
+
+// checkAdmission evaluates the admission policy against the repository of the module and returns all violations.
+func (m api) checkAdmission(ctx context.Context, moduleAddr module.Addr) ([]AdmissionViolation, error) {
+	policy := m.config.AdmissionPolicy
+	repo, err := m.getModuleRepo(moduleAddr, module.Metadata{})
+	if err != nil {
+		return nil, err
+	}
+	var violations []AdmissionViolation
+	if policy.RejectForks || policy.RequireDescription {
+		info, err := m.vcsClient.GetRepositoryInfo(ctx, repo.addr)
+		if err != nil {
+			return nil, err
+		}
+		if policy.RejectForks && info.ForkOf != nil {
+			violations = append(violations, AdmissionViolation{
+				AdmissionRuleNoForks,
+				"the repository is a fork of " + info.ForkOf.String(),
+			})
+		}
+		if policy.RequireDescription && strings.TrimSpace(info.Description) == "" {
+			violations = append(violations, AdmissionViolation{
+				AdmissionRuleDescription,
+				"the repository has no description",
+			})
+		}
+	}
+	if policy.RequireVersionTag {
+		found, err := m.hasVersionTag(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			violations = append(violations, AdmissionViolation{
+				AdmissionRuleVersionTag,
+				"the repository has no tag that is a valid semantic version",
+			})
+		}
+	}
+	return violations, nil
+}
+
+// hasVersionTag returns true if the repository has at least one tag that is a valid semantic version. The full tag
+// list is only fetched if none of the latest tags are valid.
+func (m api) hasVersionTag(ctx context.Context, repo moduleRepo) (bool, error) {
+	for _, listTags := range []func(context.Context, vcs.RepositoryAddr) ([]vcs.Version, error){
+		m.vcsClient.ListLatestTags,
+		m.vcsClient.ListAllTags,
+	} {
+		tags, err := listTags(ctx, repo.addr)
+		if err != nil {
+			return false, err
+		}
+		for _, tag := range tags {
+			ver, err := module.VersionFromVCS(tag.VersionNumber)
+			if err != nil {
+				continue
+			}
+			if _, err := ver.ParseSemVer(); err == nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestAddModuleAdmissionPolicy(t *testing.T) {
+	upstream := vcs.RepositoryAddr{Org: "upstream", Name: "terraform-aws-iam"}
+	policy := libregistry.AdmissionPolicy{
+		RejectForks:        true,
+		RequireVersionTag:  true,
+		RequireDescription: true,
+	}
+
+	for _, tc := range []struct {
+		name          string
+		info          vcs.RepositoryInfo
+		tags          []vcs.VersionNumber
+		expectedRules []libregistry.AdmissionRule
+	}{
+		{
+			name: "admitted",
+			info: vcs.RepositoryInfo{Description: "IAM module"},
+			tags: []vcs.VersionNumber{"v1.0.0"},
+		},
+		{
+			name:          "fork",
+			info:          vcs.RepositoryInfo{Description: "IAM module", ForkOf: &upstream},
+			tags:          []vcs.VersionNumber{"v1.0.0"},
+			expectedRules: []libregistry.AdmissionRule{libregistry.AdmissionRuleNoForks},
+		},
+		{
+			name: "empty",
+			info: vcs.RepositoryInfo{ForkOf: &upstream},
+			expectedRules: []libregistry.AdmissionRule{
+				libregistry.AdmissionRuleNoForks,
+				libregistry.AdmissionRuleDescription,
+				libregistry.AdmissionRuleVersionTag,
+			},
+		},
+		{
+			name:          "invalid-tag",
+			info:          vcs.RepositoryInfo{Description: "IAM module"},
+			tags:          []vcs.VersionNumber{"v1.0"},
+			expectedRules: []libregistry.AdmissionRule{libregistry.AdmissionRuleVersionTag},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inMemoryVCS := fakevcs.New()
+			ctx := context.Background()
+			dataAPI, err := metadata.New(memory.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithAdmissionPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := vcs.RepositoryAddr{Org: "test", Name: "terraform-aws-iam"}
+			if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.CreateRepository(repo, tc.info); err != nil {
+				t.Fatal(err)
+			}
+			for _, tag := range tc.tags {
+				if err := inMemoryVCS.CreateVersion(repo, tag, os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = registry.AddModule(ctx, repo.String())
+			if len(tc.expectedRules) == 0 {
+				if err != nil {
+					t.Fatalf("Failed to add module (%v)", err)
+				}
+				return
+			}
+			var rejectedErr *libregistry.ModuleRejectedError
+			if !errors.As(err, &rejectedErr) {
+				t.Fatalf("Expected a ModuleRejectedError, got %v", err)
+			}
+			if len(rejectedErr.Violations) != len(tc.expectedRules) {
+				t.Fatalf("Incorrect number of violations: %v", rejectedErr.Violations)
+			}
+			for i, rule := range tc.expectedRules {
+				if rejectedErr.Violations[i].Rule != rule {
+					t.Fatalf("Incorrect violation %d: expected %s, got %s", i, rule, rejectedErr.Violations[i].Rule)
+				}
+			}
+			modules, err := dataAPI.ListModules(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(modules) != 0 {
+				t.Fatalf("A rejected module was added.")
+			}
+		})
+	}
+}
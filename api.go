@@ -5,31 +5,97 @@ package libregistry
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/opentofu/libregistry/metadata"
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
 	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/multi"
 )
 
 // API describes the API interface for accessing the registry.
 type API interface {
 	// AddModule adds a module based on a VCS repository. The VCS repository name must follow the naming convention
-	// of the VCS implementation passed to the registry API on initialization.
-	AddModule(ctx context.Context, vcsRepository string) error
+	// of the VCS implementation passed to the registry API on initialization. Returns a *ModuleBlockedError if the
+	// module or its namespace is on the blocklist.
+	//
+	// Pass WithSubmitter to verify that the submitting user may act on behalf of the organization owning the
+	// repository. Permission failures are reported as *SubmitterRequiredError, *SubmitterNotPermittedError or
+	// *PermissionCheckFailedError.
+	AddModule(ctx context.Context, vcsRepository string, opts ...AddModuleOpt) error
 	// UpdateModule updates the list of available versions for a module in the registry from its source repository.
-	// This function is idempotent and adds the module to the storage if it does not exist yet.
-	UpdateModule(ctx context.Context, moduleAddr module.Addr) error
+	// This function is idempotent and adds the module to the storage if it does not exist yet, unless the module is
+	// blocklisted. The result describes the added, removed and unchanged versions.
+	UpdateModule(ctx context.Context, moduleAddr module.Addr) (ModuleUpdateResult, error)
+	// SetModuleSource sets the custom repository and the tag mapping of a module and updates its versions from the
+	// new source. Pass an empty custom repository to use the repository matching the module address, and a nil tag
+	// mapping to use all tags as version numbers. If the source changes, only the yanked and deprecated versions are
+	// kept from the previous source. Like UpdateModule, this adds the module if it does not exist yet, unless the
+	// module is blocklisted. If the update fails, the previous source is restored.
+	SetModuleSource(ctx context.Context, moduleAddr module.Addr, customRepository string, tagMapping *module.TagMapping) (ModuleUpdateResult, error)
+	// AnalyzeModuleVersion checks out a module version, extracts the variables, outputs, required providers and
+	// resources of the root module, the submodules and the examples, and stores the results alongside the module.
+	AnalyzeModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error
+	// UpdateAllModules runs UpdateModule for all modules in the registry. A failing module does not stop the other
+	// updates, its error is recorded in the report instead. The error is only returned if the modules cannot be
+	// listed or the context is canceled, in which case the report covers the modules processed so far.
+	UpdateAllModules(ctx context.Context, opts UpdateAllOptions) (UpdateAllReport, error)
+	// RemoveModule deletes the module, the analysis results of its versions and, if WithDocsHarvester was passed, its
+	// documentation, and adds the module to the blocklist with the given reason, so AddModule and UpdateModule will
+	// not recreate it. Removing a module that does not exist only blocks it.
+	RemoveModule(ctx context.Context, moduleAddr module.Addr, reason string) error
+	// MigrateModule checks if the repository of a module was renamed or transferred. If so, the metadata, the
+	// analysis results and, if WithDocsHarvester was passed, the documentation are moved to the address matching the
+	// new repository, and an alias is left at the old address. If the module has a custom repository or the new repository does not follow the naming convention,
+	// only the custom repository is updated. Returns the current address of the module.
+	MigrateModule(ctx context.Context, moduleAddr module.Addr) (module.Addr, error)
+	// MigrateProvider works like MigrateModule for providers. This requires the data API passed to New to also
+	// implement metadata.ProviderDataAPI. The namespace keys are not copied when a provider moves to a new namespace.
+	MigrateProvider(ctx context.Context, providerAddr provider.Addr) (provider.Addr, error)
 }
 
 // New creates a new instance of the registry API with the given GitHub client and data API instance.
-func New(vcsClient vcs.Client, dataAPI metadata.ModuleDataAPI) (API, error) {
+func New(vcsClient vcs.Client, dataAPI metadata.ModuleDataAPI, opts ...Opt) (API, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+
+	if len(config.VCSClients) > 0 {
+		multiOpts := []multi.Opt{multi.WithDefaultBackend(vcsClient)}
+		for host, client := range config.VCSClients {
+			multiOpts = append(multiOpts, multi.WithBackend(host, client))
+		}
+		var err error
+		vcsClient, err = multi.New(multiOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the VCS clients (%w)", err)
+		}
+	}
+
+	providerDataAPI, _ := dataAPI.(metadata.ProviderDataAPI)
+	if config.DryRun != nil {
+		dataAPI = dryRunDataAPI{dataAPI, config.DryRun}
+		// Provider writes are not recorded, so provider operations are disabled in dry-run mode.
+		providerDataAPI = nil
+	}
+
 	return &api{
-		dataAPI,
-		vcsClient,
+		config:          config,
+		dataAPI:         dataAPI,
+		providerDataAPI: providerDataAPI,
+		vcsClient:       vcsClient,
 	}, nil
 }
 
 type api struct {
-	dataAPI   metadata.ModuleDataAPI
-	vcsClient vcs.Client
+	config  Config
+	dataAPI metadata.ModuleDataAPI
+	// providerDataAPI is nil if the data API does not support providers.
+	providerDataAPI metadata.ProviderDataAPI
+	vcsClient       vcs.Client
 }
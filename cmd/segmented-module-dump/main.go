@@ -43,7 +43,7 @@ func main() {
 	batch := 0
 	fmt.Printf("Provider\tVersions\tBatch\n")
 	for _, moduleAddr := range providers {
-		module, err := meta.GetModule(ctx, moduleAddr)
+		module, err := meta.GetModule(ctx, moduleAddr, false)
 		if err != nil {
 			_, _ = os.Stderr.Write([]byte(err.Error()))
 			os.Exit(1)
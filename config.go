@@ -0,0 +1,166 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/libregistry/docs"
+	"github.com/opentofu/libregistry/license"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the registry API.
+type Config struct {
+	// DetectLicenses enables checking out each new version to detect its licenses when updating a module. The
+	// detected licenses are stored on the version.
+	DetectLicenses bool
+	// LicensePolicy is applied to the latest version when adding a module. Setting a policy also enables
+	// DetectLicenses. Defaults to no policy.
+	LicensePolicy *license.Policy
+	// AdmissionPolicy describes the requirements a repository must meet when adding a module. Defaults to nil,
+	// admitting all repositories.
+	AdmissionPolicy *AdmissionPolicy
+	// VCSClients holds additional VCS clients by host. Modules with a custom repository that starts with one of these
+	// hosts, e.g. gitlab.example.com/org/repo, are fetched using the corresponding client. The clients are combined
+	// with the default client using the vcs/multi client.
+	VCSClients map[string]vcs.Client
+	// DryRun enables the dry-run mode if set. In dry-run mode, all VCS lookups are performed, but the metadata
+	// changes are recorded in the DryRunRecorder instead of being written. Defaults to nil, writing the changes.
+	DryRun *DryRunRecorder
+	// RequireSubmitter rejects AddModule calls without a submitter. Defaults to false, which skips the ownership check
+	// if no submitter is given.
+	RequireSubmitter bool
+	// Admins lists the users who may add modules to any namespace, bypassing the ownership check.
+	Admins []vcs.Username
+	// AllowedBots lists the bot accounts, such as the account running the registry automation, that may add modules
+	// to any namespace.
+	AllowedBots []vcs.Username
+	// DocsHarvester is used to move or delete the documentation harvested by the docs package along with the module
+	// metadata. Defaults to nil, leaving the documentation untouched. The documentation is not changed in dry-run
+	// mode.
+	DocsHarvester docs.Harvester
+
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.LicensePolicy != nil {
+		c.DetectLicenses = true
+	}
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// WithLicenseDetection enables detecting the licenses of each new module version.
+func WithLicenseDetection(detect bool) Opt {
+	return func(config *Config) error {
+		config.DetectLicenses = detect
+		return nil
+	}
+}
+
+// WithLicensePolicy sets the license policy applied when adding a module. This also enables license detection.
+func WithLicensePolicy(policy license.Policy) Opt {
+	return func(config *Config) error {
+		config.LicensePolicy = &policy
+		return nil
+	}
+}
+
+// WithAdmissionPolicy sets the requirements a repository must meet when adding a module.
+func WithAdmissionPolicy(policy AdmissionPolicy) Opt {
+	return func(config *Config) error {
+		config.AdmissionPolicy = &policy
+		return nil
+	}
+}
+
+// WithVCSClient registers a VCS client for modules with a custom repository on the specified host.
+func WithVCSClient(host string, client vcs.Client) Opt {
+	return func(config *Config) error {
+		if host == "" || strings.Contains(host, "/") {
+			return fmt.Errorf("invalid VCS host: %q", host)
+		}
+		if config.VCSClients == nil {
+			config.VCSClients = map[string]vcs.Client{}
+		}
+		config.VCSClients[host] = client
+		return nil
+	}
+}
+
+// WithDryRun enables the dry-run mode. The changes that would have been written are recorded in the recorder, which
+// can produce a diff of the affected JSON documents.
+func WithDryRun(recorder *DryRunRecorder) Opt {
+	return func(config *Config) error {
+		if recorder == nil {
+			return fmt.Errorf("the dry-run recorder must not be nil")
+		}
+		config.DryRun = recorder
+		return nil
+	}
+}
+
+// WithDocsHarvester sets the documentation harvester whose documentation is moved or deleted along with the module
+// metadata when a module is migrated or removed.
+func WithDocsHarvester(harvester docs.Harvester) Opt {
+	return func(config *Config) error {
+		if harvester == nil {
+			return fmt.Errorf("the docs harvester must not be nil")
+		}
+		config.DocsHarvester = harvester
+		return nil
+	}
+}
+
+// WithRequireSubmitter rejects AddModule calls that do not pass a submitter using WithSubmitter.
+func WithRequireSubmitter(require bool) Opt {
+	return func(config *Config) error {
+		config.RequireSubmitter = require
+		return nil
+	}
+}
+
+// WithAdmins adds users who may add modules to any namespace.
+func WithAdmins(admins ...vcs.Username) Opt {
+	return func(config *Config) error {
+		for _, admin := range admins {
+			if err := admin.Validate(); err != nil {
+				return err
+			}
+		}
+		config.Admins = append(config.Admins, admins...)
+		return nil
+	}
+}
+
+// WithAllowedBots adds bot accounts that may add modules to any namespace.
+func WithAllowedBots(bots ...vcs.Username) Opt {
+	return func(config *Config) error {
+		for _, bot := range bots {
+			if err := bot.Validate(); err != nil {
+				return err
+			}
+		}
+		config.AllowedBots = append(config.AllowedBots, bots...)
+		return nil
+	}
+}
+
+// WithLogger sets the logger to use.
+func WithLogger(log logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = log.WithName("Registry")
+		return nil
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+// DocsNotFoundError indicates that no documentation has been harvested for the specified version.
+type DocsNotFoundError struct {
+	// Addr is the module or provider address.
+	Addr    string
+	Version string
+	Cause   error
+}
+
+func (d *DocsNotFoundError) Error() string {
+	return "Documentation not found: " + d.Addr + " " + d.Version
+}
+
+func (d *DocsNotFoundError) Unwrap() error {
+	return d.Cause
+}
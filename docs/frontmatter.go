@@ -0,0 +1,77 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseFrontmatter splits the YAML frontmatter from a document and returns its top level string values and the
+// remaining document. Only the subset of YAML used in provider documentation is supported: plain, quoted and block
+// scalars. If the document has no frontmatter, the values are empty and the document is returned unchanged.
+func parseFrontmatter(document []byte) (map[string]string, []byte) {
+	text := strings.ReplaceAll(string(document), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return map[string]string{}, document
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end == -1 {
+		return map[string]string{}, document
+	}
+	header := text[4 : 4+end]
+	body := text[4+end+4:]
+	// Remove the rest of the closing line.
+	if i := strings.Index(body, "\n"); i != -1 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+
+	values := map[string]string{}
+	lines := strings.Split(header, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" || strings.HasPrefix(line, "#") || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch value {
+		case "|", "|-", "|+", ">", ">-", ">+":
+			var block []string
+			for i+1 < len(lines) && (lines[i+1] == "" || lines[i+1][0] == ' ' || lines[i+1][0] == '\t') {
+				i++
+				block = append(block, strings.TrimSpace(lines[i]))
+			}
+			separator := "\n"
+			if value[0] == '>' {
+				separator = " "
+			}
+			values[key] = strings.TrimSpace(strings.Join(block, separator))
+		default:
+			values[key] = unquote(value)
+		}
+	}
+	return values, []byte(strings.TrimLeft(body, "\n"))
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+			return value[1 : len(value)-1]
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	return value
+}
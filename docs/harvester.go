@@ -0,0 +1,108 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package docs collects the documentation of module and provider versions from their repositories and stores it in a
+// normalized form in the registry storage.
+package docs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// Harvester checks out module and provider versions and stores their documentation.
+type Harvester interface {
+	// HarvestModuleVersion checks out a single module version and stores its README.md, CHANGELOG.md and LICENSE
+	// files. Existing documentation for the version is replaced.
+	HarvestModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (ModuleDocs, error)
+	// HarvestModule harvests the documentation for all versions in the module metadata that have no documentation
+	// stored yet. It honours the custom repository, the recorded tag of each version and the subdirectory of the tag
+	// mapping.
+	HarvestModule(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata) error
+	// GetModuleDocs returns the documentation index of a module version.
+	GetModuleDocs(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (ModuleDocs, error)
+	// MoveModuleDocs moves the documentation of all versions of a module to a new address, for example after the
+	// module was migrated to a renamed repository. Existing documentation at the new address is replaced.
+	MoveModuleDocs(ctx context.Context, from module.Addr, to module.Addr) error
+	// DeleteModuleDocs deletes the documentation of all versions of a module.
+	DeleteModuleDocs(ctx context.Context, moduleAddr module.Addr) error
+
+	// HarvestProviderVersion checks out a single provider version and stores the documents found in the docs/ or,
+	// for older providers, the website/docs/ directory. Existing documentation for the version is replaced.
+	HarvestProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) (ProviderDocs, error)
+	// HarvestProvider harvests the documentation for all versions in the provider metadata that have no documentation
+	// stored yet. The custom repository in the metadata is taken into account.
+	HarvestProvider(ctx context.Context, providerAddr provider.Addr, metadata provider.Metadata) error
+	// GetProviderDocs returns the documentation index of a provider version.
+	GetProviderDocs(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) (ProviderDocs, error)
+}
+
+// New creates a new documentation harvester that reads from the specified VCS client and writes to the storage.
+func New(vcsClient vcs.Client, storageAPI storage.API, opts ...Opt) (Harvester, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+
+	return &harvester{
+		config:     config,
+		vcsClient:  vcsClient,
+		storageAPI: storageAPI,
+	}, nil
+}
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the documentation harvester.
+type Config struct {
+	// MaxFileSize is the largest document size in bytes that is stored. Larger documents are skipped. Defaults to
+	// 5 MiB.
+	MaxFileSize int64
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.MaxFileSize == 0 {
+		c.MaxFileSize = 5 * 1024 * 1024
+	}
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// WithMaxFileSize sets the largest document size in bytes that is stored.
+func WithMaxFileSize(maxFileSize int64) Opt {
+	return func(config *Config) error {
+		if maxFileSize <= 0 {
+			return fmt.Errorf("the maximum file size must be positive")
+		}
+		config.MaxFileSize = maxFileSize
+		return nil
+	}
+}
+
+// WithLogger sets the logger to use.
+func WithLogger(log logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = log.WithName("Docs")
+		return nil
+	}
+}
+
+type harvester struct {
+	config     Config
+	vcsClient  vcs.Client
+	storageAPI storage.API
+}
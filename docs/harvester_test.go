@@ -0,0 +1,256 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/docs"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestHarvestModule(t *testing.T) {
+	ctx := context.Background()
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	vcsClient := fakevcs.New()
+	createRepository(t, vcsClient, moduleAddr.ToRepositoryAddr(), "v1.0.0", fstest.MapFS{
+		"README.md":   {Data: []byte("# IAM module")},
+		"LICENSE":     {Data: []byte("Mozilla Public License Version 2.0")},
+		"LICENSE.txt": {Data: []byte("Duplicate")},
+		"main.tf":     {Data: []byte("")},
+	})
+	storageAPI := memory.New()
+	harvester, err := docs.New(vcsClient, storageAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := harvester.HarvestModule(ctx, moduleAddr, module.Metadata{Versions: []module.Version{{Version: "v1.0.0"}}}); err != nil {
+		t.Fatalf("Failed to harvest module docs (%v)", err)
+	}
+	index, err := harvester.GetModuleDocs(ctx, moduleAddr, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to read module docs (%v)", err)
+	}
+	if index.Changelog != "" {
+		t.Fatalf("Unexpected changelog: %s", index.Changelog)
+	}
+	readme, err := storageAPI.GetFile(ctx, index.Readme)
+	if err != nil {
+		t.Fatalf("Failed to read README (%v)", err)
+	}
+	if string(readme) != "# IAM module" {
+		t.Fatalf("Incorrect README: %s", readme)
+	}
+	license, err := storageAPI.GetFile(ctx, index.License)
+	if err != nil {
+		t.Fatalf("Failed to read LICENSE (%v)", err)
+	}
+	if string(license) != "Mozilla Public License Version 2.0" {
+		t.Fatalf("Incorrect LICENSE: %s", license)
+	}
+
+	_, err = harvester.GetModuleDocs(ctx, moduleAddr, "v2.0.0")
+	var notFound *docs.DocsNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Incorrect error returned for missing docs: %v", err)
+	}
+}
+
+func TestMoveModuleDocs(t *testing.T) {
+	ctx := context.Background()
+	oldAddr := module.Addr{
+		Namespace:    "old",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	newAddr := module.Addr{
+		Namespace:    "new",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	vcsClient := fakevcs.New()
+	createRepository(t, vcsClient, oldAddr.ToRepositoryAddr(), "v1.0.0", fstest.MapFS{
+		"README.md": {Data: []byte("# IAM module")},
+	})
+	storageAPI := memory.New()
+	harvester, err := docs.New(vcsClient, storageAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := harvester.HarvestModuleVersion(ctx, oldAddr, "v1.0.0"); err != nil {
+		t.Fatalf("Failed to harvest module docs (%v)", err)
+	}
+
+	if err := harvester.MoveModuleDocs(ctx, oldAddr, newAddr); err != nil {
+		t.Fatalf("Failed to move module docs (%v)", err)
+	}
+	index, err := harvester.GetModuleDocs(ctx, newAddr, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to read moved module docs (%v)", err)
+	}
+	readme, err := storageAPI.GetFile(ctx, index.Readme)
+	if err != nil {
+		t.Fatalf("Failed to read moved README (%v)", err)
+	}
+	if string(readme) != "# IAM module" {
+		t.Fatalf("Incorrect README: %s", readme)
+	}
+	var notFound *docs.DocsNotFoundError
+	if _, err := harvester.GetModuleDocs(ctx, oldAddr, "v1.0.0"); !errors.As(err, &notFound) {
+		t.Fatalf("The docs were not removed from the old address (%v)", err)
+	}
+}
+
+func TestHarvestProvider(t *testing.T) {
+	ctx := context.Background()
+	providerAddr := provider.Addr{
+		Namespace: "test",
+		Name:      "example",
+	}
+	vcsClient := fakevcs.New()
+	createRepository(t, vcsClient, providerAddr.ToRepositoryAddr(), "v1.0.0", fstest.MapFS{
+		"docs/index.md": {Data: []byte("---\npage_title: \"Provider: Example\"\n---\n\n# Example provider\n")},
+		"docs/resources/thing.md": {Data: []byte(`---
+subcategory: "Things"
+page_title: 'example_thing Resource - it''s a thing'
+description: |-
+  Manages a thing.
+  Really.
+---
+# example_thing
+`)},
+		"docs/data-sources/thing.md":           {Data: []byte("# example_thing data source\n")},
+		"docs/cdktf/python/resources/thing.md": {Data: []byte("ignored")},
+		"website/docs/r/legacy.html.markdown":  {Data: []byte("ignored")},
+		"docs/resources/notes.txt":             {Data: []byte("ignored")},
+	})
+	createRepository(t, vcsClient, vcs.RepositoryAddr{Org: "test", Name: "terraform-provider-legacy"}, "v0.1.0", fstest.MapFS{
+		"website/docs/index.html.markdown":   {Data: []byte("# Legacy provider\n")},
+		"website/docs/r/thing.html.markdown": {Data: []byte("---\nsubcategory: Things\nlayout: example\n---\n# legacy_thing\n")},
+		"website/docs/d/thing.html.md":       {Data: []byte("# legacy_thing data source\n")},
+	})
+	storageAPI := memory.New()
+	harvester, err := docs.New(vcsClient, storageAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := harvester.HarvestProviderVersion(ctx, providerAddr, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to harvest provider docs (%v)", err)
+	}
+	if index.Source != "docs" {
+		t.Fatalf("Incorrect docs source: %s", index.Source)
+	}
+	if len(index.Docs) != 3 {
+		t.Fatalf("Incorrect number of docs: %d (%v)", len(index.Docs), index.Docs)
+	}
+	var resource docs.ProviderDoc
+	for _, doc := range index.Docs {
+		if doc.Kind == docs.DocKindResource {
+			resource = doc
+		}
+	}
+	if resource.Name != "thing" || resource.Subcategory != "Things" || resource.Title != "example_thing Resource - it's a thing" || resource.Description != "Manages a thing.\nReally." {
+		t.Fatalf("Incorrect resource doc: %v", resource)
+	}
+	contents, err := storageAPI.GetFile(ctx, resource.Path)
+	if err != nil {
+		t.Fatalf("Failed to read resource doc (%v)", err)
+	}
+	if string(contents) != "# example_thing\n" {
+		t.Fatalf("Frontmatter was not removed from the stored doc: %s", contents)
+	}
+
+	legacyAddr := provider.Addr{Namespace: "test", Name: "legacy"}
+	if err := harvester.HarvestProvider(ctx, legacyAddr, provider.Metadata{Versions: []provider.Version{{Version: "v0.1.0"}}}); err != nil {
+		t.Fatalf("Failed to harvest legacy provider docs (%v)", err)
+	}
+	legacyIndex, err := harvester.GetProviderDocs(ctx, legacyAddr, "v0.1.0")
+	if err != nil {
+		t.Fatalf("Failed to read legacy provider docs (%v)", err)
+	}
+	if legacyIndex.Source != "website/docs" || len(legacyIndex.Docs) != 3 {
+		t.Fatalf("Incorrect legacy provider docs: %v", legacyIndex)
+	}
+	for _, doc := range legacyIndex.Docs {
+		if doc.Kind == docs.DocKindResource && (doc.Subcategory != "Things" || doc.Path != "docs/providers/t/test/legacy/v0.1.0/resources/thing.md") {
+			t.Fatalf("Incorrect legacy resource doc: %v", doc)
+		}
+	}
+}
+
+// TestInvalidAddr tests that the entry points reject empty and invalid addresses instead of panicking when building
+// the storage path.
+func TestInvalidAddr(t *testing.T) {
+	ctx := context.Background()
+	harvester, err := docs.New(fakevcs.New(), memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, moduleAddr := range map[string]module.Addr{
+		"empty":   {},
+		"invalid": {Namespace: "test/other", Name: "aws", TargetSystem: "iam"},
+	} {
+		t.Run("module-"+name, func(t *testing.T) {
+			var invalidAddr *module.InvalidModuleAddrError
+			if _, err := harvester.HarvestModuleVersion(ctx, moduleAddr, "v1.0.0"); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from HarvestModuleVersion (%v)", err)
+			}
+			if err := harvester.HarvestModule(ctx, moduleAddr, module.Metadata{Versions: []module.Version{{Version: "v1.0.0"}}}); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from HarvestModule (%v)", err)
+			}
+			if _, err := harvester.GetModuleDocs(ctx, moduleAddr, "v1.0.0"); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from GetModuleDocs (%v)", err)
+			}
+		})
+	}
+
+	for name, providerAddr := range map[string]provider.Addr{
+		"empty":   {},
+		"invalid": {Namespace: "test/other", Name: "example"},
+	} {
+		t.Run("provider-"+name, func(t *testing.T) {
+			var invalidAddr *provider.InvalidProviderAddrError
+			if _, err := harvester.HarvestProviderVersion(ctx, providerAddr, "v1.0.0"); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from HarvestProviderVersion (%v)", err)
+			}
+			if err := harvester.HarvestProvider(ctx, providerAddr, provider.Metadata{Versions: []provider.Version{{Version: "v1.0.0"}}}); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from HarvestProvider (%v)", err)
+			}
+			if _, err := harvester.GetProviderDocs(ctx, providerAddr, "v1.0.0"); !errors.As(err, &invalidAddr) {
+				t.Fatalf("Incorrect error from GetProviderDocs (%v)", err)
+			}
+		})
+	}
+}
+
+func createRepository(t *testing.T, vcsClient fakevcs.VCSClient, repository vcs.RepositoryAddr, version vcs.VersionNumber, contents fstest.MapFS) {
+	t.Helper()
+	if err := vcsClient.CreateOrganization(repository.Org); err != nil {
+		var exists *fakevcs.OrganizationAlreadyExistsError
+		if !errors.As(err, &exists) {
+			t.Fatal(err)
+		}
+	}
+	if err := vcsClient.CreateRepository(repository, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := vcsClient.CreateVersion(repository, version, contents); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// moduleFiles maps the lowercase file names in the repository root to the normalized file names in the storage.
+var moduleFiles = map[string]string{
+	"readme.md":    "README.md",
+	"changelog.md": "CHANGELOG.md",
+	"license":      "LICENSE",
+	"license.md":   "LICENSE",
+	"license.txt":  "LICENSE",
+}
+
+func (h *harvester) HarvestModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (ModuleDocs, error) {
+	return h.harvestModuleVersion(ctx, moduleAddr, moduleAddr.ToRepositoryAddr(), module.Version{Version: version}, "")
+}
+
+// harvestModuleVersion collects the documentation of a module version from the tag of the version. If subdirectory is
+// not empty, the documents are read from this directory instead of the repository root.
+func (h *harvester) harvestModuleVersion(ctx context.Context, moduleAddr module.Addr, repository vcs.RepositoryAddr, ver module.Version, subdirectory string) (ModuleDocs, error) {
+	version := ver.Version
+	if err := moduleAddr.Validate(); err != nil {
+		return ModuleDocs{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return ModuleDocs{}, err
+	}
+
+	workingCopy, err := h.vcsClient.Checkout(ctx, repository, ver.ToVCSVersion())
+	if err != nil {
+		return ModuleDocs{}, fmt.Errorf("failed to check out %s version %s (%w)", moduleAddr, version, err)
+	}
+	defer func() {
+		if err := workingCopy.Close(); err != nil {
+			h.config.Logger.Warn(ctx, "Failed to close working copy for %s version %s (%v)", moduleAddr, version, err)
+		}
+	}()
+
+	moduleDir := "."
+	if subdirectory != "" {
+		moduleDir = subdirectory
+	}
+	entries, err := fs.ReadDir(workingCopy, moduleDir)
+	if err != nil {
+		return ModuleDocs{}, fmt.Errorf("failed to read the module directory of %s version %s (%w)", moduleAddr, version, err)
+	}
+
+	dir := getModuleDocsDirectory(moduleAddr, version)
+	if err := h.deleteDirectory(ctx, dir); err != nil {
+		return ModuleDocs{}, err
+	}
+
+	result := ModuleDocs{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		target, ok := moduleFiles[strings.ToLower(entry.Name())]
+		if !ok {
+			continue
+		}
+		targetPath := storage.Path(path.Join(string(dir), target))
+		var field *storage.Path
+		switch target {
+		case "README.md":
+			field = &result.Readme
+		case "CHANGELOG.md":
+			field = &result.Changelog
+		default:
+			field = &result.License
+		}
+		if *field != "" {
+			// Prefer the first match, e.g. LICENSE over LICENSE.md.
+			continue
+		}
+		contents, ok, err := h.readFile(ctx, workingCopy, path.Join(moduleDir, entry.Name()))
+		if err != nil {
+			return ModuleDocs{}, err
+		}
+		if !ok {
+			continue
+		}
+		if err := h.storageAPI.PutFile(ctx, targetPath, contents); err != nil {
+			return ModuleDocs{}, fmt.Errorf("failed to write %s (%w)", targetPath, err)
+		}
+		*field = targetPath
+	}
+
+	if err := h.putIndex(ctx, dir, result); err != nil {
+		return ModuleDocs{}, err
+	}
+	return result, nil
+}
+
+func (h *harvester) HarvestModule(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return err
+	}
+	repository := moduleAddr.ToRepositoryAddr()
+	if metadata.CustomRepository != "" {
+		var err error
+		repository, err = h.vcsClient.ParseRepositoryAddr(metadata.CustomRepository)
+		if err != nil {
+			return fmt.Errorf("failed to parse custom repository %s for %s (%w)", metadata.CustomRepository, moduleAddr, err)
+		}
+	}
+	for _, version := range metadata.Versions {
+		exists, err := h.indexExists(ctx, getModuleDocsDirectory(moduleAddr, version.Version))
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := h.harvestModuleVersion(ctx, moduleAddr, repository, version, metadata.TagMapping.GetSubdirectory()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *harvester) GetModuleDocs(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (ModuleDocs, error) {
+	if err := moduleAddr.Validate(); err != nil {
+		return ModuleDocs{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return ModuleDocs{}, err
+	}
+	var result ModuleDocs
+	if err := h.getIndex(ctx, getModuleDocsDirectory(moduleAddr, version), moduleAddr.String(), string(version), &result); err != nil {
+		return ModuleDocs{}, err
+	}
+	return result, nil
+}
+
+func (h *harvester) MoveModuleDocs(ctx context.Context, from module.Addr, to module.Addr) error {
+	if err := from.Validate(); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	fromDir := getModuleDocsRoot(from)
+	toDir := getModuleDocsRoot(to)
+	if fromDir == toDir {
+		return nil
+	}
+	if err := h.deleteDirectory(ctx, toDir); err != nil {
+		return err
+	}
+	if err := h.copyDirectory(ctx, fromDir, toDir); err != nil {
+		return err
+	}
+
+	// The indexes hold the storage paths of the documents, which need to point to the new address.
+	versions, err := h.storageAPI.ListDirectories(ctx, toDir)
+	if err != nil {
+		return fmt.Errorf("failed to list directories in %s (%w)", toDir, err)
+	}
+	for _, version := range versions {
+		dir := storage.Path(path.Join(string(toDir), version))
+		exists, err := h.indexExists(ctx, dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		var index ModuleDocs
+		if err := h.getIndex(ctx, dir, to.String(), version, &index); err != nil {
+			return err
+		}
+		index.Readme = rebasePath(index.Readme, fromDir, toDir)
+		index.Changelog = rebasePath(index.Changelog, fromDir, toDir)
+		index.License = rebasePath(index.License, fromDir, toDir)
+		if err := h.putIndex(ctx, dir, index); err != nil {
+			return err
+		}
+	}
+	return h.deleteDirectory(ctx, fromDir)
+}
+
+func (h *harvester) DeleteModuleDocs(ctx context.Context, moduleAddr module.Addr) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return err
+	}
+	return h.deleteDirectory(ctx, getModuleDocsRoot(moduleAddr))
+}
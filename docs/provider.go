@@ -0,0 +1,240 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// providerDocsLayout describes where a documentation directory keeps the different kinds of documents.
+type providerDocsLayout struct {
+	directory      string
+	subdirectories map[string]DocKind
+}
+
+// providerDocsLayouts lists the supported documentation directories in order of preference. The docs/ directory is
+// the current layout, while website/docs/ is the legacy layout with abbreviated directory names.
+var providerDocsLayouts = []providerDocsLayout{
+	{
+		directory: "docs",
+		subdirectories: map[string]DocKind{
+			"resources":    DocKindResource,
+			"data-sources": DocKindDataSource,
+			"guides":       DocKindGuide,
+			"functions":    DocKindFunction,
+		},
+	},
+	{
+		directory: "website/docs",
+		subdirectories: map[string]DocKind{
+			"r":         DocKindResource,
+			"d":         DocKindDataSource,
+			"guides":    DocKindGuide,
+			"functions": DocKindFunction,
+		},
+	},
+}
+
+// docKindDirectories maps the document kinds to their directory in the storage.
+var docKindDirectories = map[DocKind]string{
+	DocKindResource:   "resources",
+	DocKindDataSource: "data-sources",
+	DocKindGuide:      "guides",
+	DocKindFunction:   "functions",
+}
+
+// docExtensions lists the recognized document extensions, longest first.
+var docExtensions = []string{".html.markdown", ".html.md", ".markdown", ".md"}
+
+var docNameRe = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+func (h *harvester) HarvestProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) (ProviderDocs, error) {
+	return h.harvestProviderVersion(ctx, providerAddr, providerAddr.ToRepositoryAddr(), version)
+}
+
+func (h *harvester) harvestProviderVersion(ctx context.Context, providerAddr provider.Addr, repository vcs.RepositoryAddr, version provider.VersionNumber) (ProviderDocs, error) {
+	if err := providerAddr.Validate(); err != nil {
+		return ProviderDocs{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return ProviderDocs{}, err
+	}
+
+	directories := make([]string, len(providerDocsLayouts))
+	for i, layout := range providerDocsLayouts {
+		directories[i] = layout.directory
+	}
+	workingCopy, err := h.vcsClient.SparseCheckout(ctx, repository, version.ToVCSVersion(), directories...)
+	if err != nil {
+		return ProviderDocs{}, fmt.Errorf("failed to check out %s version %s (%w)", providerAddr, version, err)
+	}
+	defer func() {
+		if err := workingCopy.Close(); err != nil {
+			h.config.Logger.Warn(ctx, "Failed to close working copy for %s version %s (%v)", providerAddr, version, err)
+		}
+	}()
+
+	dir := getProviderDocsDirectory(providerAddr, version)
+	if err := h.deleteDirectory(ctx, dir); err != nil {
+		return ProviderDocs{}, err
+	}
+
+	result := ProviderDocs{
+		Docs: []ProviderDoc{},
+	}
+	for _, layout := range providerDocsLayouts {
+		docs, err := h.harvestProviderDocsLayout(ctx, workingCopy, layout, dir)
+		if err != nil {
+			return ProviderDocs{}, err
+		}
+		if len(docs) > 0 {
+			result.Source = layout.directory
+			result.Docs = docs
+			break
+		}
+	}
+
+	if err := h.putIndex(ctx, dir, result); err != nil {
+		return ProviderDocs{}, err
+	}
+	return result, nil
+}
+
+func (h *harvester) harvestProviderDocsLayout(ctx context.Context, workingCopy vcs.WorkingCopy, layout providerDocsLayout, dir storage.Path) ([]ProviderDoc, error) {
+	entries, err := fs.ReadDir(workingCopy, layout.directory)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s (%w)", layout.directory, err)
+	}
+
+	var result []ProviderDoc
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			name, ok := docName(entry.Name())
+			if !ok || name != "index" {
+				continue
+			}
+			doc, ok, err := h.harvestProviderDoc(ctx, workingCopy, path.Join(layout.directory, entry.Name()), DocKindOverview, name, storage.Path(path.Join(string(dir), "index.md")))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, doc)
+			}
+			continue
+		}
+		kind, ok := layout.subdirectories[entry.Name()]
+		if !ok {
+			continue
+		}
+		subdirectory := path.Join(layout.directory, entry.Name())
+		files, err := fs.ReadDir(workingCopy, subdirectory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s (%w)", subdirectory, err)
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			name, ok := docName(file.Name())
+			if !ok {
+				h.config.Logger.Debug(ctx, "Skipping %s/%s because it is not a recognized document.", subdirectory, file.Name())
+				continue
+			}
+			target := storage.Path(path.Join(string(dir), docKindDirectories[kind], name+".md"))
+			doc, ok, err := h.harvestProviderDoc(ctx, workingCopy, path.Join(subdirectory, file.Name()), kind, name, target)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, doc)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (h *harvester) harvestProviderDoc(ctx context.Context, workingCopy vcs.WorkingCopy, file string, kind DocKind, name string, target storage.Path) (ProviderDoc, bool, error) {
+	contents, ok, err := h.readFile(ctx, workingCopy, file)
+	if err != nil || !ok {
+		return ProviderDoc{}, false, err
+	}
+	frontmatter, body := parseFrontmatter(contents)
+	if err := h.storageAPI.PutFile(ctx, target, body); err != nil {
+		return ProviderDoc{}, false, fmt.Errorf("failed to write %s (%w)", target, err)
+	}
+	return ProviderDoc{
+		Kind:        kind,
+		Name:        name,
+		Title:       frontmatter["page_title"],
+		Subcategory: frontmatter["subcategory"],
+		Description: frontmatter["description"],
+		Path:        target,
+	}, true, nil
+}
+
+// docName returns the document name without the extension. It returns false if the file is not a document or the
+// name cannot be stored.
+func docName(fileName string) (string, bool) {
+	for _, extension := range docExtensions {
+		if strings.HasSuffix(fileName, extension) {
+			name := strings.TrimSuffix(fileName, extension)
+			return name, name != "" && docNameRe.MatchString(name)
+		}
+	}
+	return "", false
+}
+
+func (h *harvester) HarvestProvider(ctx context.Context, providerAddr provider.Addr, metadata provider.Metadata) error {
+	if err := providerAddr.Validate(); err != nil {
+		return err
+	}
+	repository := providerAddr.ToRepositoryAddr()
+	if metadata.CustomRepository != "" {
+		var err error
+		repository, err = h.vcsClient.ParseRepositoryAddr(metadata.CustomRepository)
+		if err != nil {
+			return fmt.Errorf("failed to parse custom repository %s for %s (%w)", metadata.CustomRepository, providerAddr, err)
+		}
+	}
+	for _, version := range metadata.Versions {
+		exists, err := h.indexExists(ctx, getProviderDocsDirectory(providerAddr, version.Version))
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := h.harvestProviderVersion(ctx, providerAddr, repository, version.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *harvester) GetProviderDocs(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) (ProviderDocs, error) {
+	if err := providerAddr.Validate(); err != nil {
+		return ProviderDocs{}, err
+	}
+	if err := version.Validate(); err != nil {
+		return ProviderDocs{}, err
+	}
+	var result ProviderDocs
+	if err := h.getIndex(ctx, getProviderDocsDirectory(providerAddr, version), providerAddr.String(), string(version), &result); err != nil {
+		return ProviderDocs{}, err
+	}
+	return result, nil
+}
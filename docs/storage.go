@@ -0,0 +1,159 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+const docsDirectory = "docs"
+const indexFile = "index.json"
+
+// getModuleDocsRoot returns the directory holding the documentation of all versions of a module.
+func getModuleDocsRoot(moduleAddr module.Addr) storage.Path {
+	moduleAddr = moduleAddr.Normalize()
+	return storage.Path(path.Join(docsDirectory, "modules", moduleAddr.Namespace[0:1], moduleAddr.Namespace, moduleAddr.Name, moduleAddr.TargetSystem))
+}
+
+func getModuleDocsDirectory(moduleAddr module.Addr, version module.VersionNumber) storage.Path {
+	return storage.Path(path.Join(string(getModuleDocsRoot(moduleAddr)), string(version.Normalize())))
+}
+
+func getProviderDocsDirectory(providerAddr provider.Addr, version provider.VersionNumber) storage.Path {
+	providerAddr = providerAddr.Normalize()
+	return storage.Path(path.Join(docsDirectory, "providers", providerAddr.Namespace[0:1], providerAddr.Namespace, providerAddr.Name, string(version.Normalize())))
+}
+
+// readFile reads a file from the working copy, returning false if the file exceeds the maximum file size.
+func (h *harvester) readFile(ctx context.Context, fsys fs.FS, file string) ([]byte, bool, error) {
+	fh, err := fsys.Open(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open %s (%w)", file, err)
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+	contents, err := io.ReadAll(io.LimitReader(fh, h.config.MaxFileSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s (%w)", file, err)
+	}
+	if int64(len(contents)) > h.config.MaxFileSize {
+		h.config.Logger.Warn(ctx, "Skipping %s because it is larger than %d bytes.", file, h.config.MaxFileSize)
+		return nil, false, nil
+	}
+	return contents, true, nil
+}
+
+func (h *harvester) putIndex(ctx context.Context, dir storage.Path, index any) error {
+	marshalled, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal docs index (%w)", err)
+	}
+	p := storage.Path(path.Join(string(dir), indexFile))
+	if err := h.storageAPI.PutFile(ctx, p, marshalled); err != nil {
+		return fmt.Errorf("failed to write docs index %s (%w)", p, err)
+	}
+	return nil
+}
+
+// getIndex reads the docs index from the specified directory. The addr and version are only used for the error
+// message.
+func (h *harvester) getIndex(ctx context.Context, dir storage.Path, addr string, version string, index any) error {
+	p := storage.Path(path.Join(string(dir), indexFile))
+	contents, err := h.storageAPI.GetFile(ctx, p)
+	if err != nil {
+		var notFoundErr *storage.ErrFileNotFound
+		if errors.As(err, &notFoundErr) {
+			return &DocsNotFoundError{
+				Addr:    addr,
+				Version: version,
+				Cause:   err,
+			}
+		}
+		return fmt.Errorf("failed to read docs index %s (%w)", p, err)
+	}
+	if err := json.Unmarshal(contents, index); err != nil {
+		return fmt.Errorf("failed to parse docs index %s (%w)", p, err)
+	}
+	return nil
+}
+
+func (h *harvester) indexExists(ctx context.Context, dir storage.Path) (bool, error) {
+	return h.storageAPI.FileExists(ctx, storage.Path(path.Join(string(dir), indexFile)))
+}
+
+// deleteDirectory removes all files in a storage directory recursively so no stale documents remain when a version
+// is harvested again.
+func (h *harvester) deleteDirectory(ctx context.Context, dir storage.Path) error {
+	files, err := h.storageAPI.ListFiles(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to list files in %s (%w)", dir, err)
+	}
+	for _, file := range files {
+		p := storage.Path(path.Join(string(dir), file))
+		if err := h.storageAPI.DeleteFile(ctx, p); err != nil {
+			return fmt.Errorf("failed to delete %s (%w)", p, err)
+		}
+	}
+	subdirectories, err := h.storageAPI.ListDirectories(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to list directories in %s (%w)", dir, err)
+	}
+	for _, subdirectory := range subdirectories {
+		if err := h.deleteDirectory(ctx, storage.Path(path.Join(string(dir), subdirectory))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDirectory copies all files in a storage directory recursively to the target directory.
+func (h *harvester) copyDirectory(ctx context.Context, from storage.Path, to storage.Path) error {
+	files, err := h.storageAPI.ListFiles(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to list files in %s (%w)", from, err)
+	}
+	for _, file := range files {
+		source := storage.Path(path.Join(string(from), file))
+		contents, err := h.storageAPI.GetFile(ctx, source)
+		if err != nil {
+			return fmt.Errorf("failed to read %s (%w)", source, err)
+		}
+		target := storage.Path(path.Join(string(to), file))
+		if err := h.storageAPI.PutFile(ctx, target, contents); err != nil {
+			return fmt.Errorf("failed to write %s (%w)", target, err)
+		}
+	}
+	subdirectories, err := h.storageAPI.ListDirectories(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to list directories in %s (%w)", from, err)
+	}
+	for _, subdirectory := range subdirectories {
+		if err := h.copyDirectory(ctx, storage.Path(path.Join(string(from), subdirectory)), storage.Path(path.Join(string(to), subdirectory))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebasePath moves a storage path inside the from directory to the same location inside the to directory. Other paths
+// are returned unchanged.
+func rebasePath(p storage.Path, from storage.Path, to storage.Path) storage.Path {
+	relative, ok := strings.CutPrefix(string(p), string(from)+"/")
+	if !ok {
+		return p
+	}
+	return storage.Path(path.Join(string(to), relative))
+}
@@ -0,0 +1,51 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package docs
+
+import (
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// ModuleDocs is the documentation index of a module version. This structure represents the file in
+// docs/modules/o/opentofu/somemodule/platform/v1.0.0/index.json. Files that were not found in the repository are
+// left empty.
+type ModuleDocs struct {
+	Readme    storage.Path `json:"readme,omitempty"`
+	Changelog storage.Path `json:"changelog,omitempty"`
+	License   storage.Path `json:"license,omitempty"`
+}
+
+// ProviderDocs is the documentation index of a provider version. This structure represents the file in
+// docs/providers/o/opentofu/someprovider/v1.0.0/index.json.
+type ProviderDocs struct {
+	// Source is the directory the documentation was read from, either "docs" or "website/docs".
+	Source string        `json:"source"`
+	Docs   []ProviderDoc `json:"docs"`
+}
+
+// DocKind describes the kind of provider document.
+type DocKind string
+
+const (
+	DocKindOverview   DocKind = "overview"
+	DocKindResource   DocKind = "resource"
+	DocKindDataSource DocKind = "data-source"
+	DocKindGuide      DocKind = "guide"
+	DocKindFunction   DocKind = "function"
+)
+
+// ProviderDoc describes a single provider document.
+type ProviderDoc struct {
+	Kind DocKind `json:"kind"`
+	// Name is the file name of the document without the extension, e.g. the resource name without the provider prefix.
+	Name string `json:"name"`
+	// Title is the page_title from the frontmatter.
+	Title string `json:"title,omitempty"`
+	// Subcategory is the subcategory from the frontmatter.
+	Subcategory string `json:"subcategory,omitempty"`
+	// Description is the description from the frontmatter.
+	Description string `json:"description,omitempty"`
+	// Path is the storage path of the document contents. The frontmatter is removed from the stored document.
+	Path storage.Path `json:"path"`
+}
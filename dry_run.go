@@ -0,0 +1,439 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+// DryRunRecorder collects the writes the registry API would have made in dry-run mode. Pass it to New using
+// WithDryRun. The recorder is safe for concurrent use and can be shared between calls to see their combined effect.
+type DryRunRecorder struct {
+	lock   sync.Mutex
+	writes map[string]*PlannedWrite
+}
+
+// NewDryRunRecorder creates an empty recorder.
+func NewDryRunRecorder() *DryRunRecorder {
+	return &DryRunRecorder{
+		writes: map[string]*PlannedWrite{},
+	}
+}
+
+// PlannedWrite describes a single document that would have been written in dry-run mode.
+type PlannedWrite struct {
+	// Name identifies the document, e.g. "modules/test/aws/iam" or "module-details/test/aws/iam/v1.0.0".
+	Name string
+	// Before holds the indented JSON of the stored document, or nil if it does not exist.
+	Before []byte
+	// After holds the indented JSON the document would have, or nil if it would be deleted.
+	After []byte
+}
+
+// Writes returns the documents that would change, sorted by name. Documents that would be written with their
+// current content are omitted.
+func (r *DryRunRecorder) Writes() []PlannedWrite {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := make([]PlannedWrite, 0, len(r.writes))
+	for _, write := range r.writes {
+		if bytes.Equal(write.Before, write.After) {
+			continue
+		}
+		result = append(result, *write)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Diff returns a unified diff of all documents that would change. It is empty if nothing would change.
+func (r *DryRunRecorder) Diff() string {
+	var result strings.Builder
+	for _, write := range r.Writes() {
+		result.WriteString(unifiedDiff(write.Name, write.Before, write.After))
+	}
+	return result.String()
+}
+
+// record stores the new state of a document. The original state is only read on the first write, so the recorded
+// change covers all writes to the document.
+func (r *DryRunRecorder) record(name string, original func() ([]byte, error), after any) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	write, ok := r.writes[name]
+	if !ok {
+		before, err := original()
+		if err != nil {
+			return err
+		}
+		write = &PlannedWrite{Name: name, Before: before}
+		r.writes[name] = write
+	}
+	if after == nil {
+		write.After = nil
+		return nil
+	}
+	marshalled, err := marshalDryRun(after)
+	if err != nil {
+		return err
+	}
+	write.After = marshalled
+	return nil
+}
+
+// pendingNames returns the names of the recorded documents starting with the prefix. The value is false if the
+// document would be deleted.
+func (r *DryRunRecorder) pendingNames(prefix string) map[string]bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := map[string]bool{}
+	for name, write := range r.writes {
+		if strings.HasPrefix(name, prefix) {
+			result[name] = write.After != nil
+		}
+	}
+	return result
+}
+
+// pending returns the recorded state of a document. The second return value is false if there is no recorded write.
+func (r *DryRunRecorder) pending(name string, target any) (bool, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	write, ok := r.writes[name]
+	if !ok {
+		return false, false, nil
+	}
+	if write.After == nil {
+		return true, false, nil
+	}
+	return true, true, json.Unmarshal(write.After, target)
+}
+
+func marshalDryRun(value any) ([]byte, error) {
+	marshalled, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dry-run document (%w)", err)
+	}
+	return append(marshalled, '\n'), nil
+}
+
+// dryRunDataAPI records all writes in a DryRunRecorder instead of passing them to the underlying data API. Reads
+// return the recorded state, so later steps of an operation see the effect of earlier ones. The data API is not
+// embedded on purpose: a write function added to metadata.ModuleDataAPI must break the build here instead of
+// silently writing to the storage in dry-run mode.
+type dryRunDataAPI struct {
+	dataAPI  metadata.ModuleDataAPI
+	recorder *DryRunRecorder
+}
+
+var _ metadata.ModuleDataAPI = dryRunDataAPI{}
+
+const dryRunModuleBlocklistName = "blocklist/modules"
+const dryRunModuleAliasesName = "aliases/modules"
+
+// dryRunModuleAlias is a single alias in the recorded alias list.
+type dryRunModuleAlias struct {
+	From module.Addr `json:"from"`
+	To   module.Addr `json:"to"`
+}
+
+func dryRunModuleName(moduleAddr module.Addr) string {
+	moduleAddr = moduleAddr.Normalize()
+	return "modules/" + moduleAddr.Namespace + "/" + moduleAddr.Name + "/" + moduleAddr.TargetSystem
+}
+
+func dryRunModuleDetailsName(moduleAddr module.Addr, version module.VersionNumber) string {
+	moduleAddr = moduleAddr.Normalize()
+	return "module-details/" + moduleAddr.Namespace + "/" + moduleAddr.Name + "/" + moduleAddr.TargetSystem + "/" + string(version.Normalize())
+}
+
+// parseDryRunModuleName is the inverse of dryRunModuleName.
+func parseDryRunModuleName(name string) (module.Addr, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 4 || parts[0] != "modules" {
+		return module.Addr{}, false
+	}
+	return module.Addr{Namespace: parts[1], Name: parts[2], TargetSystem: parts[3]}, true
+}
+
+// mergeModules adds the modules recorded as written to the stored modules and removes the modules recorded as
+// deleted. Only recorded modules matching the filter are added.
+func (d dryRunDataAPI) mergeModules(stored []module.Addr, filter func(moduleAddr module.Addr) bool) []module.Addr {
+	recorded := map[module.Addr]bool{}
+	for name, exists := range d.recorder.pendingNames("modules/") {
+		if moduleAddr, ok := parseDryRunModuleName(name); ok && filter(moduleAddr) {
+			recorded[moduleAddr] = exists
+		}
+	}
+	var result []module.Addr
+	for _, moduleAddr := range stored {
+		exists, ok := recorded[moduleAddr.Normalize()]
+		if ok {
+			delete(recorded, moduleAddr.Normalize())
+			if !exists {
+				continue
+			}
+		}
+		result = append(result, moduleAddr)
+	}
+	for moduleAddr, exists := range recorded {
+		if exists {
+			result = append(result, moduleAddr)
+		}
+	}
+	return result
+}
+
+func (d dryRunDataAPI) ListModules(ctx context.Context) ([]module.Addr, error) {
+	stored, err := d.dataAPI.ListModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.mergeModules(stored, func(module.Addr) bool { return true }), nil
+}
+
+func (d dryRunDataAPI) ListModulesByNamespace(ctx context.Context, namespace string) ([]module.Addr, error) {
+	stored, err := d.dataAPI.ListModulesByNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	namespace = module.NormalizeNamespace(namespace)
+	return d.mergeModules(stored, func(moduleAddr module.Addr) bool {
+		return moduleAddr.Namespace == namespace
+	}), nil
+}
+
+func (d dryRunDataAPI) ListModulesByNamespaceAndName(ctx context.Context, namespace string, name string) ([]module.Addr, error) {
+	stored, err := d.dataAPI.ListModulesByNamespaceAndName(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	namespace = module.NormalizeNamespace(namespace)
+	name = module.NormalizeName(name)
+	return d.mergeModules(stored, func(moduleAddr module.Addr) bool {
+		return moduleAddr.Namespace == namespace && moduleAddr.Name == name
+	}), nil
+}
+
+func (d dryRunDataAPI) GetAllModules(ctx context.Context) (map[module.Addr]module.Metadata, error) {
+	moduleAddrs, err := d.ListModules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[module.Addr]module.Metadata, len(moduleAddrs))
+	for _, moduleAddr := range moduleAddrs {
+		result[moduleAddr], err = d.GetModule(ctx, moduleAddr, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (d dryRunDataAPI) GetModule(ctx context.Context, moduleAddr module.Addr, resolveAliases bool) (module.Metadata, error) {
+	if resolveAliases {
+		var err error
+		moduleAddr, err = d.GetModuleCanonicalAddr(ctx, moduleAddr)
+		if err != nil {
+			return module.Metadata{}, err
+		}
+	}
+	var result module.Metadata
+	found, exists, err := d.recorder.pending(dryRunModuleName(moduleAddr), &result)
+	if err != nil {
+		return module.Metadata{}, err
+	}
+	if !found {
+		return d.dataAPI.GetModule(ctx, moduleAddr, false)
+	}
+	if !exists {
+		return module.Metadata{}, &metadata.ModuleNotFoundError{ModuleAddr: moduleAddr}
+	}
+	return result, nil
+}
+
+func (d dryRunDataAPI) GetModuleCanonicalAddr(ctx context.Context, moduleAddr module.Addr) (module.Addr, error) {
+	moduleAddr = moduleAddr.Normalize()
+	aliases, err := d.ListModuleAliases(ctx)
+	if err != nil {
+		return moduleAddr, err
+	}
+	if targetAddr, ok := aliases[moduleAddr]; ok {
+		moduleAddr = targetAddr
+	}
+	if _, err := d.GetModule(ctx, moduleAddr, false); err != nil {
+		return moduleAddr, err
+	}
+	return moduleAddr, nil
+}
+
+func (d dryRunDataAPI) PutModule(ctx context.Context, moduleAddr module.Addr, moduleMetadata module.Metadata) error {
+	return d.recorder.record(dryRunModuleName(moduleAddr), d.originalModule(ctx, moduleAddr), moduleMetadata)
+}
+
+func (d dryRunDataAPI) DeleteModule(ctx context.Context, moduleAddr module.Addr) error {
+	return d.recorder.record(dryRunModuleName(moduleAddr), d.originalModule(ctx, moduleAddr), nil)
+}
+
+func (d dryRunDataAPI) originalModule(ctx context.Context, moduleAddr module.Addr) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		original, err := d.dataAPI.GetModule(ctx, moduleAddr, false)
+		if err != nil {
+			var notFoundErr *metadata.ModuleNotFoundError
+			if errors.As(err, &notFoundErr) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return marshalDryRun(original)
+	}
+}
+
+func (d dryRunDataAPI) GetModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (module.VersionDetails, error) {
+	var result module.VersionDetails
+	found, exists, err := d.recorder.pending(dryRunModuleDetailsName(moduleAddr, version), &result)
+	if err != nil {
+		return module.VersionDetails{}, err
+	}
+	if !found {
+		return d.dataAPI.GetModuleVersionDetails(ctx, moduleAddr, version)
+	}
+	if !exists {
+		return module.VersionDetails{}, &metadata.ModuleVersionDetailsNotFoundError{ModuleAddr: moduleAddr, Version: version}
+	}
+	return result, nil
+}
+
+func (d dryRunDataAPI) PutModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, details module.VersionDetails) error {
+	return d.recorder.record(dryRunModuleDetailsName(moduleAddr, version), d.originalModuleDetails(ctx, moduleAddr, version), details)
+}
+
+func (d dryRunDataAPI) DeleteModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	return d.recorder.record(dryRunModuleDetailsName(moduleAddr, version), d.originalModuleDetails(ctx, moduleAddr, version), nil)
+}
+
+func (d dryRunDataAPI) originalModuleDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		original, err := d.dataAPI.GetModuleVersionDetails(ctx, moduleAddr, version)
+		if err != nil {
+			var notFoundErr *metadata.ModuleVersionDetailsNotFoundError
+			if errors.As(err, &notFoundErr) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return marshalDryRun(original)
+	}
+}
+
+func (d dryRunDataAPI) GetModuleBlocklist(ctx context.Context) (module.Blocklist, error) {
+	var result module.Blocklist
+	found, _, err := d.recorder.pending(dryRunModuleBlocklistName, &result)
+	if err != nil {
+		return module.Blocklist{}, err
+	}
+	if !found {
+		return d.dataAPI.GetModuleBlocklist(ctx)
+	}
+	return result, nil
+}
+
+func (d dryRunDataAPI) PutModuleBlocklist(ctx context.Context, blocklist module.Blocklist) error {
+	return d.recorder.record(dryRunModuleBlocklistName, func() ([]byte, error) {
+		original, err := d.dataAPI.GetModuleBlocklist(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(original.Modules) == 0 && len(original.Namespaces) == 0 {
+			return nil, nil
+		}
+		return marshalDryRun(original)
+	}, blocklist)
+}
+
+func (d dryRunDataAPI) ListModuleAliases(ctx context.Context) (map[module.Addr]module.Addr, error) {
+	var recorded []dryRunModuleAlias
+	found, _, err := d.recorder.pending(dryRunModuleAliasesName, &recorded)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return d.dataAPI.ListModuleAliases(ctx)
+	}
+	result := make(map[module.Addr]module.Addr, len(recorded))
+	for _, alias := range recorded {
+		result[alias.From.Normalize()] = alias.To.Normalize()
+	}
+	return result, nil
+}
+
+// PutModuleAlias applies the same rules as the metadata API: aliases pointing to the old address are updated and an
+// alias for the new address is removed.
+func (d dryRunDataAPI) PutModuleAlias(ctx context.Context, from module.Addr, to module.Addr) error {
+	from = from.Normalize()
+	to = to.Normalize()
+	if from == to {
+		return fmt.Errorf("cannot create an alias from %s to itself", from)
+	}
+	aliases, err := d.ListModuleAliases(ctx)
+	if err != nil {
+		return err
+	}
+	for alias, target := range aliases {
+		if target == from {
+			aliases[alias] = to
+		}
+	}
+	delete(aliases, to)
+	aliases[from] = to
+	return d.recorder.record(dryRunModuleAliasesName, func() ([]byte, error) {
+		original, err := d.dataAPI.ListModuleAliases(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(original) == 0 {
+			return nil, nil
+		}
+		return marshalDryRun(sortedDryRunModuleAliases(original))
+	}, sortedDryRunModuleAliases(aliases))
+}
+
+func sortedDryRunModuleAliases(aliases map[module.Addr]module.Addr) []dryRunModuleAlias {
+	result := make([]dryRunModuleAlias, 0, len(aliases))
+	for from, to := range aliases {
+		result = append(result, dryRunModuleAlias{from, to})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].From.String() < result[j].From.String()
+	})
+	return result
+}
+
+func (d dryRunDataAPI) YankModuleVersion(_ context.Context, _ module.Addr, _ module.VersionNumber, _ string) error {
+	return &DryRunNotSupportedError{"YankModuleVersion"}
+}
+
+func (d dryRunDataAPI) UnyankModuleVersion(_ context.Context, _ module.Addr, _ module.VersionNumber) error {
+	return &DryRunNotSupportedError{"UnyankModuleVersion"}
+}
+
+func (d dryRunDataAPI) DeprecateModuleVersion(_ context.Context, _ module.Addr, _ module.VersionNumber, _ string) error {
+	return &DryRunNotSupportedError{"DeprecateModuleVersion"}
+}
+
+func (d dryRunDataAPI) UndeprecateModuleVersion(_ context.Context, _ module.Addr, _ module.VersionNumber) error {
+	return &DryRunNotSupportedError{"UndeprecateModuleVersion"}
+}
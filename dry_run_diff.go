@@ -0,0 +1,159 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change.
+const diffContextLines = 3
+
+// maxDiffCells limits the size of the table used to find the longest common subsequence. Larger changes are shown
+// as a complete replacement of the differing part.
+const maxDiffCells = 4 * 1024 * 1024
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff between two documents. A nil document is shown as /dev/null.
+func unifiedDiff(name string, before []byte, after []byte) string {
+	beforeName, afterName := "a/"+name, "b/"+name
+	if before == nil {
+		beforeName = "/dev/null"
+	}
+	if after == nil {
+		afterName = "/dev/null"
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	// beforeLines[i] and afterLines[i] hold the number of lines of each document before operation i.
+	beforeLines := make([]int, len(ops)+1)
+	afterLines := make([]int, len(ops)+1)
+	var changes []int
+	for i, op := range ops {
+		beforeLines[i+1], afterLines[i+1] = beforeLines[i], afterLines[i]
+		if op.kind != '+' {
+			beforeLines[i+1]++
+		}
+		if op.kind != '-' {
+			afterLines[i+1]++
+		}
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString("--- " + beforeName + "\n")
+	result.WriteString("+++ " + afterName + "\n")
+	for first := 0; first < len(changes); {
+		// Merge changes whose context would overlap into a single hunk.
+		last := first
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContextLines {
+			last++
+		}
+		start := max(changes[first]-diffContextLines, 0)
+		end := min(changes[last]+1+diffContextLines, len(ops))
+
+		beforeStart, beforeCount := beforeLines[start]+1, beforeLines[end]-beforeLines[start]
+		afterStart, afterCount := afterLines[start]+1, afterLines[end]-afterLines[start]
+		if beforeCount == 0 {
+			beforeStart--
+		}
+		if afterCount == 0 {
+			afterStart--
+		}
+		result.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", beforeStart, beforeCount, afterStart, afterCount))
+		for _, op := range ops[start:end] {
+			result.WriteString(string(op.kind) + op.line + "\n")
+		}
+		first = last + 1
+	}
+	return result.String()
+}
+
+func splitLines(document []byte) []string {
+	if len(document) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(document), "\n"), "\n")
+}
+
+// diffLines computes the edit script between two line lists using the longest common subsequence of the lines
+// between the common prefix and suffix.
+func diffLines(a []string, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func diffMiddle(a []string, b []string) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+	// lcs[i][j] holds the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
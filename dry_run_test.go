@@ -0,0 +1,136 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+// TestDryRun tests that no metadata is written in dry-run mode and that the recorder reports the changes.
+func TestDryRun(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := libregistry.NewDryRunRecorder()
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithDryRun(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dataAPI.GetModule(ctx, moduleAddr, false); err == nil {
+		t.Fatalf("The module was written despite the dry-run mode.")
+	} else {
+		var notFound *metadata.ModuleNotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatal(err)
+		}
+	}
+
+	writes := recorder.Writes()
+	if len(writes) != 1 || writes[0].Name != "modules/test/aws/iam" || writes[0].Before != nil {
+		t.Fatalf("Incorrect planned writes: %v", writes)
+	}
+	diff := recorder.Diff()
+	if !strings.HasPrefix(diff, "--- /dev/null\n+++ b/modules/test/aws/iam\n@@ -0,0 ") {
+		t.Fatalf("Incorrect diff header:\n%s", diff)
+	}
+	if !strings.Contains(diff, `+      "version": "v1.0.0",`) {
+		t.Fatalf("The diff does not contain the new version:\n%s", diff)
+	}
+
+	// A second update in the same dry run sees the recorded state, so nothing changes.
+	result, err := registry.UpdateModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed() {
+		t.Fatalf("The recorded state was not used in the second update: %v", result)
+	}
+}
+
+// TestDryRunListModules tests that the module listings include the modules added and exclude the modules removed
+// earlier in the same dry run.
+func TestDryRunListModules(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+	stored := module.Addr{Namespace: "test", Name: "stored", TargetSystem: "aws"}
+	added := module.Addr{Namespace: "test", Name: "added", TargetSystem: "aws"}
+	for _, moduleAddr := range []module.Addr{stored, added} {
+		repo := moduleAddr.ToRepositoryAddr()
+		if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dataAPI.PutModule(ctx, stored, module.Metadata{Versions: module.VersionList{{Version: "v1.0.0"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithDryRun(libregistry.NewDryRunRecorder()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddModule(ctx, added.ToRepositoryAddr().String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RemoveModule(ctx, stored, "Test removal"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := registry.UpdateAllModules(ctx, libregistry.UpdateAllOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Updated) != 0 || len(report.Failed) != 0 {
+		t.Fatalf("Incorrect update report: %v", report)
+	}
+	if len(report.Unchanged) != 1 || !report.Unchanged[0].Module.Equals(added) {
+		t.Fatalf("The update did not see the modules of the dry run: %v", report.Unchanged)
+	}
+	if modules, err := dataAPI.ListModules(ctx); err != nil || len(modules) != 1 || !modules[0].Equals(stored) {
+		t.Fatalf("The storage was modified in dry-run mode: %v (%v)", modules, err)
+	}
+}
@@ -4,7 +4,11 @@
 package libregistry
 
 import (
+	"strings"
+
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
 )
 
 type ModuleAlreadyExistsError struct {
@@ -40,3 +44,139 @@ func (m ModuleUpdateFailedError) Error() string {
 func (m ModuleUpdateFailedError) Unwrap() error {
 	return m.Cause
 }
+
+type ModuleAnalysisFailedError struct {
+	Module  module.Addr
+	Version module.VersionNumber
+	Cause   error
+}
+
+func (m ModuleAnalysisFailedError) Error() string {
+	return "Analyzing the module " + m.Module.String() + " version " + string(m.Version) + " failed: " + m.Cause.Error()
+}
+
+func (m ModuleAnalysisFailedError) Unwrap() error {
+	return m.Cause
+}
+
+// DryRunNotSupportedError indicates that an operation cannot be simulated in dry-run mode.
+type DryRunNotSupportedError struct {
+	Operation string
+}
+
+func (d DryRunNotSupportedError) Error() string {
+	return "Operation not supported in dry-run mode: " + d.Operation
+}
+
+// ModuleBlockedError indicates that the module or its namespace is on the blocklist and cannot be added or updated.
+type ModuleBlockedError struct {
+	Module module.Addr
+	Reason string
+}
+
+func (m ModuleBlockedError) Error() string {
+	if m.Reason != "" {
+		return "Module is blocked: " + m.Module.String() + " (" + m.Reason + ")"
+	}
+	return "Module is blocked: " + m.Module.String()
+}
+
+type ModuleRemoveFailedError struct {
+	Module module.Addr
+	Cause  error
+}
+
+func (m ModuleRemoveFailedError) Error() string {
+	return "Removing the module " + m.Module.String() + " failed: " + m.Cause.Error()
+}
+
+func (m ModuleRemoveFailedError) Unwrap() error {
+	return m.Cause
+}
+
+// SubmitterRequiredError indicates that a module was submitted without a submitter, but the registry requires one to
+// verify the ownership.
+type SubmitterRequiredError struct {
+	Module module.Addr
+}
+
+func (s SubmitterRequiredError) Error() string {
+	return "A submitter is required to add the module " + s.Module.String()
+}
+
+// SubmitterNotPermittedError indicates that the submitter is not allowed to act on behalf of the organization owning
+// the repository.
+type SubmitterNotPermittedError struct {
+	Module       module.Addr
+	Submitter    vcs.Username
+	Organization vcs.OrganizationAddr
+}
+
+func (s SubmitterNotPermittedError) Error() string {
+	return "User " + string(s.Submitter) + " is not permitted to add the module " + s.Module.String() + " on behalf of " + string(s.Organization)
+}
+
+// PermissionCheckFailedError indicates that the permissions of the submitter could not be determined.
+type PermissionCheckFailedError struct {
+	Module       module.Addr
+	Submitter    vcs.Username
+	Organization vcs.OrganizationAddr
+	Cause        error
+}
+
+func (p PermissionCheckFailedError) Error() string {
+	return "Failed to check the permissions of " + string(p.Submitter) + " on " + string(p.Organization) + " for the module " + p.Module.String() + ": " + p.Cause.Error()
+}
+
+func (p PermissionCheckFailedError) Unwrap() error {
+	return p.Cause
+}
+
+// ModuleRejectedError indicates that the repository of a module does not meet the admission policy. Violations lists
+// each rule that failed.
+type ModuleRejectedError struct {
+	Module     module.Addr
+	Violations []AdmissionViolation
+}
+
+func (m ModuleRejectedError) Error() string {
+	messages := make([]string, len(m.Violations))
+	for i, violation := range m.Violations {
+		messages[i] = violation.Message
+	}
+	return "Module " + m.Module.String() + " rejected: " + strings.Join(messages, "; ")
+}
+
+type ModuleMigrationFailedError struct {
+	Module module.Addr
+	Cause  error
+}
+
+func (m ModuleMigrationFailedError) Error() string {
+	return "Migrating the module " + m.Module.String() + " failed: " + m.Cause.Error()
+}
+
+func (m ModuleMigrationFailedError) Unwrap() error {
+	return m.Cause
+}
+
+type ProviderAlreadyExistsError struct {
+	Provider provider.Addr
+}
+
+func (p ProviderAlreadyExistsError) Error() string {
+	return "Provider already exists: " + p.Provider.String()
+}
+
+type ProviderMigrationFailedError struct {
+	Provider provider.Addr
+	Cause    error
+}
+
+func (p ProviderMigrationFailedError) Error() string {
+	return "Migrating the provider " + p.Provider.String() + " failed: " + p.Cause.Error()
+}
+
+func (p ProviderMigrationFailedError) Unwrap() error {
+	return p.Cause
+}
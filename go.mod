@@ -4,7 +4,9 @@ go 1.21
 
 require (
 	github.com/ProtonMail/gopenpgp/v2 v2.7.4
+	github.com/hashicorp/hcl/v2 v2.23.0
 	github.com/opentofu/registry-address v0.0.0-20230922120653-901b9ae4061a
+	github.com/zclconf/go-cty v1.13.1
 	golang.org/x/mod v0.14.0
 	golang.org/x/sync v0.10.0
 )
@@ -12,12 +14,17 @@ require (
 require (
 	github.com/ProtonMail/go-crypto v0.0.0-20230717121422-5aa5874ade95 // indirect
 	github.com/ProtonMail/go-mime v0.0.0-20230322103455-7d82a3887f2f // indirect
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v13 v13.0.0 // indirect
+	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
 	github.com/cloudflare/circl v1.3.7 // indirect
 	github.com/hashicorp/terraform-svchost v0.1.1 // indirect
+	github.com/mitchellh/go-wordwrap v0.0.0-20150314170334-ad45545899c7 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
 	golang.org/x/crypto v0.23.0 // indirect
 	golang.org/x/net v0.23.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
+	golang.org/x/tools v0.13.0 // indirect
 )
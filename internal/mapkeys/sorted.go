@@ -0,0 +1,19 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package mapkeys
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Sorted returns the keys of a map in ascending order.
+func Sorted[K cmp.Ordered, V any](items map[K]V) []K {
+	result := make([]K, 0, len(items))
+	for item := range items {
+		result = append(result, item)
+	}
+	slices.Sort(result)
+	return result
+}
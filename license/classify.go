@@ -0,0 +1,193 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package license identifies the licenses of a repository by matching the text of its license files against
+// characteristic phrases of well-known licenses. No network access is needed.
+package license
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// NoAssertion is returned for license files that could not be classified.
+const NoAssertion = "NOASSERTION"
+
+// maxLicenseFileSize limits how much of a license file is read.
+const maxLicenseFileSize = 1024 * 1024
+
+// licenseFilePrefixes lists the lowercase prefixes of the file names considered license files.
+var licenseFilePrefixes = []string{"license", "licence", "copying", "unlicense"}
+
+// licenseDefinition describes a license by phrases that must all be present, and phrases that must not be present in
+// the normalized license text.
+type licenseDefinition struct {
+	id       string
+	required []string
+	excluded []string
+}
+
+// licenseDefinitions holds the known licenses. More specific licenses must come before the licenses whose phrases
+// they contain, e.g. ISC before 0BSD. The GNU licenses reference each other in their text, so they are matched on
+// their full title.
+var licenseDefinitions = []licenseDefinition{
+	{id: "MPL-2.0", required: []string{"mozilla public license version 2 0"}},
+	{id: "MPL-2.0", required: []string{"mozilla public license v 2 0"}},
+	{id: "Apache-2.0", required: []string{"apache license", "version 2 0"}},
+	{id: "BUSL-1.1", required: []string{"business source license 1 1"}},
+	{id: "AGPL-3.0", required: []string{"gnu affero general public license version 3"}},
+	{id: "LGPL-3.0", required: []string{"gnu lesser general public license version 3"}},
+	{id: "LGPL-2.1", required: []string{"gnu lesser general public license version 2 1"}},
+	{id: "GPL-3.0", required: []string{"gnu general public license version 3"}},
+	{id: "GPL-2.0", required: []string{"gnu general public license version 2"}},
+	{id: "EPL-2.0", required: []string{"eclipse public license v 2 0"}},
+	{id: "BSL-1.0", required: []string{"boost software license version 1 0"}},
+	{id: "CC0-1.0", required: []string{"cc0 1 0 universal"}},
+	{id: "Unlicense", required: []string{"this is free and unencumbered software released into the public domain"}},
+	{
+		id: "MIT",
+		required: []string{
+			"permission is hereby granted free of charge to any person obtaining a copy",
+			"the above copyright notice and this permission notice shall be included",
+		},
+	},
+	{
+		id: "ISC",
+		required: []string{
+			"permission to use copy modify and or distribute this software for any purpose with or without fee is hereby granted",
+			"the above copyright notice and this permission notice appear in all copies",
+		},
+	},
+	{
+		id: "0BSD",
+		required: []string{
+			"permission to use copy modify and or distribute this software for any purpose with or without fee is hereby granted",
+		},
+	},
+	{
+		id: "BSD-3-Clause",
+		required: []string{
+			"redistribution and use in source and binary forms with or without modification are permitted",
+			"neither the name of",
+		},
+	},
+	{
+		id: "BSD-2-Clause",
+		required: []string{
+			"redistribution and use in source and binary forms with or without modification are permitted",
+		},
+		excluded: []string{"neither the name of"},
+	},
+}
+
+// Classify returns the SPDX ID of the license text, or NoAssertion if the license is not recognized.
+func Classify(text []byte) string {
+	normalized := normalize(string(text))
+	for _, definition := range licenseDefinitions {
+		if definition.matches(normalized) {
+			return definition.id
+		}
+	}
+	return NoAssertion
+}
+
+func (l licenseDefinition) matches(normalized string) bool {
+	for _, phrase := range l.required {
+		if !strings.Contains(normalized, phrase) {
+			return false
+		}
+	}
+	for _, phrase := range l.excluded {
+		if strings.Contains(normalized, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize lowercases the text and replaces all punctuation and whitespace sequences with a single space.
+func normalize(text string) string {
+	var result strings.Builder
+	result.Grow(len(text) + 2)
+	result.WriteRune(' ')
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			result.WriteRune(r)
+			space = false
+		} else if !space {
+			result.WriteRune(' ')
+			space = true
+		}
+	}
+	if !space {
+		result.WriteRune(' ')
+	}
+	return result.String()
+}
+
+// Detect classifies the license files in the root directory of the working copy and returns the sorted, unique SPDX
+// IDs. Unrecognized license files are reported as NoAssertion. If no license file is found, the result is empty.
+func Detect(workingCopy vcs.WorkingCopy) ([]string, error) {
+	return DetectFS(workingCopy)
+}
+
+// DetectFS works like Detect, but reads the license files from an arbitrary filesystem.
+func DetectFS(fsys fs.ReadDirFS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the root directory (%w)", err)
+	}
+	found := map[string]struct{}{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isLicenseFile(entry.Name()) {
+			continue
+		}
+		contents, err := readLicenseFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		found[Classify(contents)] = struct{}{}
+	}
+	result := make([]string, 0, len(found))
+	for id := range found {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range licenseFilePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func readLicenseFile(fsys fs.FS, name string) ([]byte, error) {
+	fh, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open license file %s (%w)", name, err)
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+	contents, err := io.ReadAll(io.LimitReader(fh, maxLicenseFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license file %s (%w)", name, err)
+	}
+	return contents, nil
+}
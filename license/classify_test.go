@@ -0,0 +1,116 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package license_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/license"
+)
+
+func TestClassify(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text     string
+		expected string
+	}{
+		"mpl": {
+			text:     "Mozilla Public License Version 2.0\n==================================\n\n1. Definitions",
+			expected: "MPL-2.0",
+		},
+		"apache": {
+			text:     "\n                                 Apache License\n                           Version 2.0, January 2004\n",
+			expected: "Apache-2.0",
+		},
+		"mit": {
+			text: `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.`,
+			expected: "MIT",
+		},
+		"bsd-2": {
+			text: `Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:`,
+			expected: "BSD-2-Clause",
+		},
+		"bsd-3": {
+			text: `Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+3. Neither the name of the copyright holder nor the names of its contributors`,
+			expected: "BSD-3-Clause",
+		},
+		"gpl-3": {
+			text: `GNU GENERAL PUBLIC LICENSE
+Version 3, 29 June 2007
+...licensed under version 3 of the GNU Affero General Public License...
+use the GNU Lesser General Public License instead of this License.`,
+			expected: "GPL-3.0",
+		},
+		"lgpl-2.1": {
+			text:     "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999",
+			expected: "LGPL-2.1",
+		},
+		"unknown": {
+			text:     "All rights reserved.",
+			expected: license.NoAssertion,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if result := license.Classify([]byte(tc.text)); result != tc.expected {
+				t.Fatalf("Incorrect license detected (expected: %s, got: %s)", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	licenses, err := license.DetectFS(fstest.MapFS{
+		"LICENSE-MIT":    {Data: []byte("Permission is hereby granted, free of charge, to any person obtaining a copy... The above copyright notice and this permission notice shall be included")},
+		"LICENSE-APACHE": {Data: []byte("Apache License Version 2.0")},
+		"README.md":      {Data: []byte("Mozilla Public License Version 2.0")},
+		"license/notes":  {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to detect licenses (%v)", err)
+	}
+	if len(licenses) != 2 || licenses[0] != "Apache-2.0" || licenses[1] != "MIT" {
+		t.Fatalf("Incorrect licenses detected: %v", licenses)
+	}
+
+	licenses, err = license.DetectFS(fstest.MapFS{
+		"main.tf": {Data: []byte("")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to detect licenses (%v)", err)
+	}
+	if len(licenses) != 0 {
+		t.Fatalf("Licenses detected in an unlicensed repository: %v", licenses)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := license.Policy{
+		Allowed:    []string{"MPL-2.0", "Apache-2.0"},
+		NotAllowed: license.ActionFlag,
+	}
+	if action, err := policy.Evaluate([]string{"MIT", "MPL-2.0"}); action != license.ActionAllow || err != nil {
+		t.Fatalf("Allowed license was not accepted (%s, %v)", action, err)
+	}
+	if action, err := policy.Evaluate([]string{"MIT"}); action != license.ActionFlag || err == nil {
+		t.Fatalf("Non-allowed license was not flagged (%s, %v)", action, err)
+	}
+	if action, err := policy.Evaluate([]string{license.NoAssertion}); action != license.ActionReject || err == nil {
+		t.Fatalf("Unlicensed repository was not rejected (%s, %v)", action, err)
+	}
+	if action, _ := (license.Policy{}).Evaluate([]string{"MIT"}); action != license.ActionAllow {
+		t.Fatalf("Empty policy did not allow a recognized license (%s)", action)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package license
+
+// PolicyViolationError indicates that the licenses of a repository do not satisfy the license policy.
+type PolicyViolationError struct {
+	Licenses []string
+	Reason   string
+}
+
+func (p PolicyViolationError) Error() string {
+	return "License policy violation: " + p.Reason
+}
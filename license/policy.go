@@ -0,0 +1,74 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package license
+
+import (
+	"strings"
+)
+
+// Action describes what happens to a repository that violates the license policy.
+type Action string
+
+const (
+	// ActionReject rejects the repository. This is the default.
+	ActionReject Action = "reject"
+	// ActionFlag accepts the repository, but records a warning.
+	ActionFlag Action = "flag"
+	// ActionAllow accepts the repository without a warning.
+	ActionAllow Action = "allow"
+)
+
+// Policy describes which licenses are acceptable for a repository.
+type Policy struct {
+	// Allowed lists the allowed SPDX license IDs. If empty, all recognized licenses are allowed. If a repository
+	// has several licenses, it is sufficient for one of them to be allowed.
+	Allowed []string
+	// Unlicensed is the action to take if the repository has no recognized license. Defaults to ActionReject.
+	Unlicensed Action
+	// NotAllowed is the action to take if none of the licenses of the repository are allowed. Defaults to
+	// ActionReject.
+	NotAllowed Action
+}
+
+// Evaluate checks the licenses detected in a repository against the policy and returns the action to take. If the
+// action is not ActionAllow, the returned error describes the violation.
+func (p Policy) Evaluate(licenses []string) (Action, error) {
+	var recognized []string
+	for _, license := range licenses {
+		if license != NoAssertion {
+			recognized = append(recognized, license)
+		}
+	}
+	if len(recognized) == 0 {
+		return p.violation(p.Unlicensed, &PolicyViolationError{
+			Licenses: licenses,
+			Reason:   "no recognized license found",
+		})
+	}
+	if len(p.Allowed) == 0 {
+		return ActionAllow, nil
+	}
+	for _, license := range recognized {
+		for _, allowed := range p.Allowed {
+			if strings.EqualFold(license, allowed) {
+				return ActionAllow, nil
+			}
+		}
+	}
+	return p.violation(p.NotAllowed, &PolicyViolationError{
+		Licenses: licenses,
+		Reason:   "license not allowed: " + strings.Join(recognized, ", "),
+	})
+}
+
+func (p Policy) violation(action Action, err error) (Action, error) {
+	switch action {
+	case ActionAllow:
+		return ActionAllow, nil
+	case ActionFlag:
+		return ActionFlag, err
+	default:
+		return ActionReject, err
+	}
+}
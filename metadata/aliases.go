@@ -0,0 +1,89 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+const moduleAliasesFile = "aliases/modules.json"
+const providerAliasesFile = "aliases/providers.json"
+
+// aliasAddr is the constraint for the module and provider addresses stored in alias files.
+type aliasAddr[T any] interface {
+	comparable
+	Normalize() T
+	String() string
+}
+
+// storedAlias is a single entry in an alias file.
+type storedAlias[T any] struct {
+	From T `json:"from"`
+	To   T `json:"to"`
+}
+
+// readAliases reads an alias file and returns the normalized aliases. A missing file results in an empty map.
+func readAliases[T aliasAddr[T]](ctx context.Context, storageAPI storage.API, path storage.Path) (map[T]T, error) {
+	fileContents, err := storageAPI.GetFile(ctx, path)
+	if err != nil {
+		var notFoundErr *storage.ErrFileNotFound
+		if errors.As(err, &notFoundErr) {
+			return map[T]T{}, nil
+		}
+		return nil, fmt.Errorf("failed to read alias file %s (%w)", path, err)
+	}
+	var stored []storedAlias[T]
+	if err := json.Unmarshal(fileContents, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse alias file %s (%w)", path, err)
+	}
+	result := make(map[T]T, len(stored))
+	for _, alias := range stored {
+		result[alias.From.Normalize()] = alias.To.Normalize()
+	}
+	return result, nil
+}
+
+// putAlias adds an alias to an alias file. Existing aliases pointing to the old address are updated to point to the
+// new address, so aliases never need to be resolved recursively. An alias for the new address itself is removed, since
+// the address is in use again.
+func putAlias[T aliasAddr[T]](ctx context.Context, storageAPI storage.API, path storage.Path, from T, to T) error {
+	from = from.Normalize()
+	to = to.Normalize()
+	if from == to {
+		return fmt.Errorf("cannot create an alias from %s to itself", from.String())
+	}
+	aliases, err := readAliases[T](ctx, storageAPI, path)
+	if err != nil {
+		return err
+	}
+	for alias, target := range aliases {
+		if target == from {
+			aliases[alias] = to
+		}
+	}
+	delete(aliases, to)
+	aliases[from] = to
+
+	stored := make([]storedAlias[T], 0, len(aliases))
+	for alias, target := range aliases {
+		stored = append(stored, storedAlias[T]{alias, target})
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].From.String() < stored[j].From.String()
+	})
+	marshalled, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to marshal aliases (%w)", err)
+	}
+	if err := storageAPI.PutFile(ctx, path, marshalled); err != nil {
+		return fmt.Errorf("failed to write alias file %s (%w)", path, err)
+	}
+	return nil
+}
@@ -15,11 +15,15 @@ type API interface {
 
 // New creates a new API.
 func New(storageAPI storage.API) (API, error) {
+	queryAPI, _ := storageAPI.(storage.QueryAPI)
 	return &registryDataAPI{
 		storageAPI: storageAPI,
+		queryAPI:   queryAPI,
 	}, nil
 }
 
 type registryDataAPI struct {
 	storageAPI storage.API
+	// queryAPI is nil if the storage does not support queries across directories.
+	queryAPI storage.QueryAPI
 }
@@ -18,8 +18,12 @@ type ModuleDataAPI interface {
 	// ListModulesByNamespaceAndName returns a list of modules in the given namespace and name.
 	ListModulesByNamespaceAndName(ctx context.Context, namespace string, name string) ([]module.Addr, error)
 
-	// GetModule returns the current, uncommitted state of a module.
-	GetModule(ctx context.Context, moduleAddr module.Addr) (module.Metadata, error)
+	// GetModule returns the current, uncommitted state of a module. If resolveAliases is set, the aliases left
+	// behind when modules were moved are followed.
+	GetModule(ctx context.Context, moduleAddr module.Addr, resolveAliases bool) (module.Metadata, error)
+	// GetModuleCanonicalAddr returns the current address of a module by resolving the aliases left behind when
+	// modules were moved. Returns a *ModuleNotFoundError if the module does not exist.
+	GetModuleCanonicalAddr(ctx context.Context, moduleAddr module.Addr) (module.Addr, error)
 
 	// GetAllModules returns a map of all module addresses and the metadata.
 	GetAllModules(ctx context.Context) (map[module.Addr]module.Metadata, error)
@@ -29,6 +33,43 @@ type ModuleDataAPI interface {
 	PutModule(ctx context.Context, moduleAddr module.Addr, metadata module.Metadata) error
 	// DeleteModule queues up the deletion of a given module.
 	DeleteModule(ctx context.Context, moduleAddr module.Addr) error
+
+	// YankModuleVersion marks a module version as withdrawn with the given reason. Yanked versions stay in the
+	// metadata, so they are not added again when the tags are synchronized, but should not be offered for
+	// installation. Returns a *ModuleVersionNotFoundError if the version does not exist and a
+	// *ModuleVersionAlreadyYankedError if the version is already yanked.
+	YankModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, reason string) error
+	// UnyankModuleVersion removes the yanked status from a module version. It does nothing if the version is not
+	// yanked. Returns a *ModuleVersionNotFoundError if the version does not exist.
+	UnyankModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error
+	// DeprecateModuleVersion marks a module version as deprecated with the given reason. Deprecating a version again
+	// replaces the reason and the timestamp. Returns a *ModuleVersionNotFoundError if the version does not exist.
+	DeprecateModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, reason string) error
+	// UndeprecateModuleVersion removes the deprecated status from a module version. It does nothing if the version is
+	// not deprecated. Returns a *ModuleVersionNotFoundError if the version does not exist.
+	UndeprecateModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error
+
+	// GetModuleVersionDetails returns the analysis results for a single module version.
+	GetModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (module.VersionDetails, error)
+	// PutModuleVersionDetails queues up writing the analysis results for a single module version.
+	PutModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, details module.VersionDetails) error
+	// DeleteModuleVersionDetails queues up the deletion of the analysis results for a single module version.
+	DeleteModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error
+
+	// GetModuleBlocklist returns the modules and namespaces that must not be added to the registry. If no blocklist
+	// has been stored yet, an empty blocklist is returned.
+	GetModuleBlocklist(ctx context.Context) (module.Blocklist, error)
+	// PutModuleBlocklist queues up writing the module blocklist.
+	PutModuleBlocklist(ctx context.Context, blocklist module.Blocklist) error
+
+	// ListModuleAliases returns the aliases left behind when modules were moved to a new address. The key is the old
+	// address, the value is the current address of the module.
+	ListModuleAliases(ctx context.Context) (map[module.Addr]module.Addr, error)
+	// PutModuleAlias queues up adding an alias from the old address of a module to its new address. Aliases pointing
+	// to the old address are updated to point to the new address.
+	PutModuleAlias(ctx context.Context, from module.Addr, to module.Addr) error
 }
 
 const modulesDirectory = "modules"
+const moduleDetailsDirectory = "module-details"
+const moduleBlocklistFile = "blocklist/modules.json"
@@ -0,0 +1,18 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) ListModuleAliases(ctx context.Context) (map[module.Addr]module.Addr, error) {
+	return readAliases[module.Addr](ctx, r.storageAPI, moduleAliasesFile)
+}
+
+func (r registryDataAPI) PutModuleAlias(ctx context.Context, from module.Addr, to module.Addr) error {
+	return putAlias(ctx, r.storageAPI, moduleAliasesFile, from, to)
+}
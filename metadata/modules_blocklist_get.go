@@ -0,0 +1,30 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) GetModuleBlocklist(ctx context.Context) (module.Blocklist, error) {
+	fileContents, err := r.storageAPI.GetFile(ctx, moduleBlocklistFile)
+	if err != nil {
+		var notFoundErr *storage.ErrFileNotFound
+		if errors.As(err, &notFoundErr) {
+			return module.Blocklist{}, nil
+		}
+		return module.Blocklist{}, fmt.Errorf("failed to read module blocklist file %s (%w)", moduleBlocklistFile, err)
+	}
+	var blocklist module.Blocklist
+	if err := json.Unmarshal(fileContents, &blocklist); err != nil {
+		return module.Blocklist{}, fmt.Errorf("failed to parse module blocklist file %s (%w)", moduleBlocklistFile, err)
+	}
+	return blocklist, nil
+}
@@ -0,0 +1,23 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) PutModuleBlocklist(ctx context.Context, blocklist module.Blocklist) error {
+	marshalled, err := json.Marshal(blocklist)
+	if err != nil {
+		return fmt.Errorf("failed to marshal module blocklist (%w)", err)
+	}
+	if err := r.storageAPI.PutFile(ctx, moduleBlocklistFile, marshalled); err != nil {
+		return fmt.Errorf("failed to write module blocklist file %s (%w)", moduleBlocklistFile, err)
+	}
+	return nil
+}
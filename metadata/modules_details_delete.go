@@ -0,0 +1,14 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) DeleteModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	return r.storageAPI.DeleteFile(ctx, r.getModuleVersionDetailsPath(moduleAddr, version))
+}
@@ -0,0 +1,35 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) GetModuleVersionDetails(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) (module.VersionDetails, error) {
+	path := r.getModuleVersionDetailsPath(moduleAddr, version)
+	fileContents, err := r.storageAPI.GetFile(ctx, path)
+	if err != nil {
+		var notFoundErr *storage.ErrFileNotFound
+		if errors.As(err, &notFoundErr) {
+			return module.VersionDetails{}, &ModuleVersionDetailsNotFoundError{
+				ModuleAddr: moduleAddr,
+				Version:    version,
+				Cause:      err,
+			}
+		}
+		return module.VersionDetails{}, fmt.Errorf("failed to read module details file %s (%w)", path, err)
+	}
+	var details module.VersionDetails
+	if err := json.Unmarshal(fileContents, &details); err != nil {
+		return module.VersionDetails{}, fmt.Errorf("failed to parse module details file %s (%w)", path, err)
+	}
+	return details, nil
+}
@@ -19,3 +19,35 @@ func (m ModuleNotFoundError) Error() string {
 func (m ModuleNotFoundError) Unwrap() error {
 	return m.Cause
 }
+
+type ModuleVersionDetailsNotFoundError struct {
+	ModuleAddr module.Addr
+	Version    module.VersionNumber
+	Cause      error
+}
+
+func (m ModuleVersionDetailsNotFoundError) Error() string {
+	return "Module version details not found: " + m.ModuleAddr.String() + " " + string(m.Version)
+}
+
+func (m ModuleVersionDetailsNotFoundError) Unwrap() error {
+	return m.Cause
+}
+
+type ModuleVersionNotFoundError struct {
+	ModuleAddr module.Addr
+	Version    module.VersionNumber
+}
+
+func (m ModuleVersionNotFoundError) Error() string {
+	return "Module version not found: " + m.ModuleAddr.String() + " " + string(m.Version)
+}
+
+type ModuleVersionAlreadyYankedError struct {
+	ModuleAddr module.Addr
+	Version    module.VersionNumber
+}
+
+func (m ModuleVersionAlreadyYankedError) Error() string {
+	return "Module version already yanked: " + m.ModuleAddr.String() + " " + string(m.Version)
+}
@@ -13,7 +13,14 @@ import (
 	"github.com/opentofu/libregistry/types/module"
 )
 
-func (r registryDataAPI) GetModule(ctx context.Context, moduleAddr module.Addr) (module.Metadata, error) {
+func (r registryDataAPI) GetModule(ctx context.Context, moduleAddr module.Addr, resolveAliases bool) (module.Metadata, error) {
+	if resolveAliases {
+		var err error
+		moduleAddr, err = r.GetModuleCanonicalAddr(ctx, moduleAddr)
+		if err != nil {
+			return module.Metadata{}, err
+		}
+	}
 	path := r.getModulePath(moduleAddr)
 	fileContents, err := r.storageAPI.GetFile(ctx, path)
 	if err != nil {
@@ -32,3 +39,24 @@ func (r registryDataAPI) GetModule(ctx context.Context, moduleAddr module.Addr)
 	}
 	return mod, nil
 }
+
+func (r registryDataAPI) GetModuleCanonicalAddr(ctx context.Context, moduleAddr module.Addr) (module.Addr, error) {
+	moduleAddr = moduleAddr.Normalize()
+	aliases, err := r.ListModuleAliases(ctx)
+	if err != nil {
+		return moduleAddr, err
+	}
+	if targetAddr, ok := aliases[moduleAddr]; ok {
+		moduleAddr = targetAddr
+	}
+	exists, err := r.storageAPI.FileExists(ctx, r.getModulePath(moduleAddr))
+	if err != nil {
+		return moduleAddr, err
+	}
+	if !exists {
+		return moduleAddr, &ModuleNotFoundError{
+			ModuleAddr: moduleAddr,
+		}
+	}
+	return moduleAddr, nil
+}
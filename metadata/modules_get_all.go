@@ -5,21 +5,47 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/opentofu/libregistry/types/module"
 )
 
 func (r registryDataAPI) GetAllModules(ctx context.Context) (map[module.Addr]module.Metadata, error) {
+	if r.queryAPI != nil {
+		return r.queryAllModules(ctx)
+	}
 	moduleAddrs, err := r.ListModules(ctx)
 	if err != nil {
 		return nil, err
 	}
 	result := make(map[module.Addr]module.Metadata, len(moduleAddrs))
 	for _, moduleAddr := range moduleAddrs {
-		result[moduleAddr], err = r.GetModule(ctx, moduleAddr)
+		result[moduleAddr], err = r.GetModule(ctx, moduleAddr, false)
 		if err != nil {
 			return nil, err
 		}
 	}
 	return result, nil
 }
+
+// queryAllModules reads all module metadata files with a single query.
+func (r registryDataAPI) queryAllModules(ctx context.Context) (map[module.Addr]module.Metadata, error) {
+	files, err := r.queryAPI.GetFilesRecursive(ctx, modulesDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module files (%w)", err)
+	}
+	result := make(map[module.Addr]module.Metadata, len(files))
+	for p, contents := range files {
+		moduleAddr, ok := parseModulePath(p)
+		if !ok {
+			continue
+		}
+		var mod module.Metadata
+		if err := json.Unmarshal(contents, &mod); err != nil {
+			return nil, fmt.Errorf("failed to parse module metadata file %s (%w)", p, err)
+		}
+		result[moduleAddr] = mod
+	}
+	return result, nil
+}
@@ -5,6 +5,7 @@ package metadata
 
 import (
 	"path"
+	"strings"
 
 	"github.com/opentofu/libregistry/metadata/storage"
 	"github.com/opentofu/libregistry/types/module"
@@ -14,3 +15,22 @@ func (r registryDataAPI) getModulePath(moduleAddr module.Addr) storage.Path {
 	moduleAddr = moduleAddr.Normalize()
 	return storage.Path(path.Join(modulesDirectory, moduleAddr.Namespace[0:1], moduleAddr.Namespace, moduleAddr.Name, moduleAddr.TargetSystem) + ".json")
 }
+
+func (r registryDataAPI) getModuleVersionDetailsPath(moduleAddr module.Addr, version module.VersionNumber) storage.Path {
+	moduleAddr = moduleAddr.Normalize()
+	return storage.Path(path.Join(moduleDetailsDirectory, moduleAddr.Namespace[0:1], moduleAddr.Namespace, moduleAddr.Name, moduleAddr.TargetSystem, string(version.Normalize())) + ".json")
+}
+
+// parseModulePath returns the address of the module stored in the metadata file at the specified path. The second
+// return value is false if the path does not point to a module metadata file.
+func parseModulePath(p storage.Path) (module.Addr, bool) {
+	parts := strings.Split(string(p), "/")
+	if len(parts) != 5 || parts[0] != modulesDirectory || !strings.HasSuffix(parts[4], ".json") {
+		return module.Addr{}, false
+	}
+	return module.Addr{
+		Namespace:    module.NormalizeNamespace(parts[2]),
+		Name:         module.NormalizeName(parts[3]),
+		TargetSystem: strings.ToLower(strings.TrimSuffix(parts[4], ".json")),
+	}, true
+}
@@ -15,6 +15,9 @@ import (
 )
 
 func (r registryDataAPI) ListModules(ctx context.Context) ([]module.Addr, error) {
+	if r.queryAPI != nil {
+		return r.queryModules(ctx, modulesDirectory)
+	}
 	moduleLetters, err := r.storageAPI.ListDirectories(ctx, "modules")
 	if err != nil {
 		// The modules directory does not exist:
@@ -60,6 +63,9 @@ func (r registryDataAPI) listModulesByNamespaceLetter(ctx context.Context, lette
 func (r registryDataAPI) ListModulesByNamespace(ctx context.Context, namespace string) ([]module.Addr, error) {
 	namespace = module.NormalizeNamespace(namespace)
 	p := storage.Path(path.Join(modulesDirectory, namespace[0:1], namespace))
+	if r.queryAPI != nil {
+		return r.queryModules(ctx, p)
+	}
 	directories, err := r.storageAPI.ListDirectories(ctx, p)
 	if err != nil {
 		// The namespace directory does not exist:
@@ -100,3 +106,18 @@ func (r registryDataAPI) ListModulesByNamespaceAndName(ctx context.Context, name
 	}
 	return result, nil
 }
+
+// queryModules lists the modules in the directory with a single query.
+func (r registryDataAPI) queryModules(ctx context.Context, directory storage.Path) ([]module.Addr, error) {
+	paths, err := r.queryAPI.ListFilesRecursive(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in module directory %s (%w)", directory, err)
+	}
+	var result []module.Addr
+	for _, p := range paths {
+		if moduleAddr, ok := parseModulePath(p); ok {
+			result = append(result, moduleAddr)
+		}
+	}
+	return result, nil
+}
@@ -56,7 +56,7 @@ func TestModuleCRUD(t *testing.T) {
 			Namespace:    testNamespace,
 			Name:         testName,
 			TargetSystem: testTargetSystem,
-		})
+		}, false)
 		if err == nil {
 			t.Fatalf("Fetching a non-existent module did not result in an error.")
 		}
@@ -137,7 +137,7 @@ func TestModuleCRUD(t *testing.T) {
 			Namespace:    testNamespace,
 			Name:         testName,
 			TargetSystem: testTargetSystem,
-		})
+		}, false)
 		if err != nil {
 			t.Fatalf("Failed to get module (%v)", err)
 		}
@@ -152,21 +152,21 @@ func TestModuleCRUD(t *testing.T) {
 			Namespace:    testNamespace + "x",
 			Name:         testName,
 			TargetSystem: testTargetSystem,
-		}); err == nil {
+		}, false); err == nil {
 			t.Fatalf("Getting invalid module namespace did not result in an error.")
 		}
 		if _, err = api.GetModule(ctx, module.Addr{
 			Namespace:    testNamespace,
 			Name:         testName + "x",
 			TargetSystem: testTargetSystem,
-		}); err == nil {
+		}, false); err == nil {
 			t.Fatalf("Getting invalid module name did not result in an error.")
 		}
 		if _, err = api.GetModule(ctx, module.Addr{
 			Namespace:    testNamespace,
 			Name:         testName,
 			TargetSystem: testTargetSystem + "x",
-		}); err == nil {
+		}, false); err == nil {
 			t.Fatalf("Getting invalid module target system did not result in an error.")
 		}
 	})
@@ -189,3 +189,120 @@ func TestModuleCRUD(t *testing.T) {
 	})
 	t.Run("6-list-get", checkEmpty)
 }
+
+func TestModuleBlocklist(t *testing.T) {
+	api, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	ctx := context.Background()
+
+	blocklist, err := api.GetModuleBlocklist(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get empty blocklist (%v)", err)
+	}
+	if len(blocklist.Modules) != 0 || len(blocklist.Namespaces) != 0 {
+		t.Fatalf("The blocklist is not empty.")
+	}
+
+	blockedModule := module.Addr{Namespace: "opentofu", Name: "test", TargetSystem: "aws"}
+	blocklist.BlockModule(blockedModule, module.BlocklistEntry{Reason: "removed on request"})
+	blocklist.BlockNamespace("Spam", module.BlocklistEntry{Reason: "spam"})
+	if err := api.PutModuleBlocklist(ctx, blocklist); err != nil {
+		t.Fatalf("Failed to store blocklist (%v)", err)
+	}
+
+	blocklist, err = api.GetModuleBlocklist(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get blocklist (%v)", err)
+	}
+	entry, blocked := blocklist.IsBlocked(module.Addr{Namespace: "OpenTofu", Name: "Test", TargetSystem: "AWS"})
+	if !blocked || entry.Reason != "removed on request" {
+		t.Fatalf("The module is not blocked after reloading the blocklist.")
+	}
+	if _, blocked := blocklist.IsBlocked(module.Addr{Namespace: "spam", Name: "any", TargetSystem: "aws"}); !blocked {
+		t.Fatalf("The namespace is not blocked after reloading the blocklist.")
+	}
+	if _, blocked := blocklist.IsBlocked(module.Addr{Namespace: "opentofu", Name: "other", TargetSystem: "aws"}); blocked {
+		t.Fatalf("An unrelated module is blocked.")
+	}
+}
+
+func TestModuleAliases(t *testing.T) {
+	api, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	ctx := context.Background()
+
+	first := module.Addr{Namespace: "first", Name: "test", TargetSystem: "aws"}
+	second := module.Addr{Namespace: "second", Name: "test", TargetSystem: "aws"}
+	third := module.Addr{Namespace: "third", Name: "test", TargetSystem: "aws"}
+
+	if err := api.PutModuleAlias(ctx, first, second); err != nil {
+		t.Fatalf("Failed to store alias (%v)", err)
+	}
+	if err := api.PutModuleAlias(ctx, module.Addr{Namespace: "Second", Name: "test", TargetSystem: "aws"}, third); err != nil {
+		t.Fatalf("Failed to store alias (%v)", err)
+	}
+	aliases, err := api.ListModuleAliases(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list aliases (%v)", err)
+	}
+	if len(aliases) != 2 || aliases[first] != third || aliases[second] != third {
+		t.Fatalf("The aliases were not updated to the latest address: %v", aliases)
+	}
+
+	// Moving the module back to an aliased address removes the alias.
+	if err := api.PutModuleAlias(ctx, third, first); err != nil {
+		t.Fatalf("Failed to store alias (%v)", err)
+	}
+	aliases, err = api.ListModuleAliases(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list aliases (%v)", err)
+	}
+	if len(aliases) != 2 || aliases[second] != first || aliases[third] != first {
+		t.Fatalf("Incorrect aliases after moving the module back: %v", aliases)
+	}
+}
+
+func TestGetModuleResolvesAliases(t *testing.T) {
+	api, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	ctx := context.Background()
+
+	oldAddr := module.Addr{Namespace: "old", Name: "test", TargetSystem: "aws"}
+	newAddr := module.Addr{Namespace: "new", Name: "test", TargetSystem: "aws"}
+	if err := api.PutModule(ctx, newAddr, module.Metadata{
+		Versions: module.VersionList{{Version: "v1.0.0"}},
+	}); err != nil {
+		t.Fatalf("Failed to put module (%v)", err)
+	}
+	if err := api.PutModuleAlias(ctx, oldAddr, newAddr); err != nil {
+		t.Fatalf("Failed to store alias (%v)", err)
+	}
+
+	var notFound *metadata.ModuleNotFoundError
+	if _, err := api.GetModule(ctx, oldAddr, false); !errors.As(err, &notFound) {
+		t.Fatalf("The alias was resolved without requesting it (%v)", err)
+	}
+	moduleMetadata, err := api.GetModule(ctx, module.Addr{Namespace: "Old", Name: "test", TargetSystem: "aws"}, true)
+	if err != nil {
+		t.Fatalf("Failed to get module through its alias (%v)", err)
+	}
+	if len(moduleMetadata.Versions) != 1 {
+		t.Fatalf("Incorrect module returned through the alias: %v", moduleMetadata)
+	}
+	canonicalAddr, err := api.GetModuleCanonicalAddr(ctx, oldAddr)
+	if err != nil {
+		t.Fatalf("Failed to get canonical address (%v)", err)
+	}
+	if !canonicalAddr.Equals(newAddr) {
+		t.Fatalf("Incorrect canonical address: %s", canonicalAddr)
+	}
+	if _, err := api.GetModuleCanonicalAddr(ctx, module.Addr{Namespace: "other", Name: "test", TargetSystem: "aws"}); !errors.As(err, &notFound) {
+		t.Fatalf("Incorrect error returned for a non-existent module (%v)", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (r registryDataAPI) YankModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, reason string) error {
+	return r.updateModuleVersion(ctx, moduleAddr, version, func(ver *module.Version) error {
+		if ver.Yanked != nil {
+			return &ModuleVersionAlreadyYankedError{
+				ModuleAddr: moduleAddr,
+				Version:    ver.Version,
+			}
+		}
+		ver.Yanked = newVersionStatus(reason)
+		return nil
+	})
+}
+
+func (r registryDataAPI) UnyankModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	return r.updateModuleVersion(ctx, moduleAddr, version, func(ver *module.Version) error {
+		ver.Yanked = nil
+		return nil
+	})
+}
+
+func (r registryDataAPI) DeprecateModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, reason string) error {
+	return r.updateModuleVersion(ctx, moduleAddr, version, func(ver *module.Version) error {
+		ver.Deprecated = newVersionStatus(reason)
+		return nil
+	})
+}
+
+func (r registryDataAPI) UndeprecateModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	return r.updateModuleVersion(ctx, moduleAddr, version, func(ver *module.Version) error {
+		ver.Deprecated = nil
+		return nil
+	})
+}
+
+func (r registryDataAPI) updateModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber, update func(ver *module.Version) error) error {
+	moduleMetadata, err := r.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		return err
+	}
+	for i, ver := range moduleMetadata.Versions {
+		if ver.Version.Normalize() == version.Normalize() {
+			if err := update(&moduleMetadata.Versions[i]); err != nil {
+				return err
+			}
+			return r.PutModule(ctx, moduleAddr, moduleMetadata)
+		}
+	}
+	return &ModuleVersionNotFoundError{
+		ModuleAddr: moduleAddr,
+		Version:    version,
+	}
+}
+
+func newVersionStatus(reason string) *types.VersionStatus {
+	return &types.VersionStatus{
+		Reason:    reason,
+		Timestamp: time.Now().UTC(),
+	}
+}
@@ -16,8 +16,11 @@ type ProviderDataAPI interface {
 	// also be observed in the "from" namespace.
 	ListProviderNamespaceAliases(ctx context.Context) (map[string]string, error)
 	// ListProviderAliases lists individual provider aliases and their actual provider.Addr's. This is needed to support
-	// legacy provider addresses.
+	// legacy provider addresses and providers that were moved to a new address.
 	ListProviderAliases(ctx context.Context) (map[provider.Addr]provider.Addr, error)
+	// PutProviderAlias queues up adding an alias from the old address of a provider to its new address. Aliases
+	// pointing to the old address are updated to point to the new address.
+	PutProviderAlias(ctx context.Context, from provider.Addr, to provider.Addr) error
 
 	// ListProviders returns all providers in the registry. The includeAliases parameter lets you include aliased copies
 	// of providers.
@@ -46,6 +49,21 @@ type ProviderDataAPI interface {
 	// DeleteProvider queues up deleting the specified provider.
 	DeleteProvider(ctx context.Context, addr provider.Addr) error
 
+	// YankProviderVersion marks a provider version as withdrawn with the given reason. Aliases are resolved. Returns a
+	// *ProviderVersionNotFoundError if the version does not exist and a *ProviderVersionAlreadyYankedError if the
+	// version is already yanked.
+	YankProviderVersion(ctx context.Context, addr provider.Addr, version provider.VersionNumber, reason string) error
+	// UnyankProviderVersion removes the yanked status from a provider version. Aliases are resolved. It does nothing
+	// if the version is not yanked. Returns a *ProviderVersionNotFoundError if the version does not exist.
+	UnyankProviderVersion(ctx context.Context, addr provider.Addr, version provider.VersionNumber) error
+	// DeprecateProviderVersion marks a provider version as deprecated with the given reason. Aliases are resolved.
+	// Deprecating a version again replaces the reason and the timestamp. Returns a *ProviderVersionNotFoundError if
+	// the version does not exist.
+	DeprecateProviderVersion(ctx context.Context, addr provider.Addr, version provider.VersionNumber, reason string) error
+	// UndeprecateProviderVersion removes the deprecated status from a provider version. Aliases are resolved. It does
+	// nothing if the version is not deprecated. Returns a *ProviderVersionNotFoundError if the version does not exist.
+	UndeprecateProviderVersion(ctx context.Context, addr provider.Addr, version provider.VersionNumber) error
+
 	// ListProviderNamespacesWithKeys returns a list of provider namespaces that have a key registered.
 	ListProviderNamespacesWithKeys(ctx context.Context) ([]string, error)
 	// ListProviderNamespaceKeyIDs lists the keys IDs of all keys registered in a provider namespace.
@@ -16,7 +16,7 @@ func (r registryDataAPI) ListProviderNamespaceAliases(_ context.Context) (map[st
 	}, nil
 }
 
-func (r registryDataAPI) ListProviderAliases(_ context.Context) (map[provider.Addr]provider.Addr, error) {
+func (r registryDataAPI) ListProviderAliases(ctx context.Context) (map[provider.Addr]provider.Addr, error) {
 	// TODO: move this to a JSON file.
 	aliases := map[provider.Addr]provider.Addr{
 		provider.Addr{Namespace: "opentofu", Name: "aci"}:              {Namespace: "CiscoDevNet", Name: "aci"},
@@ -102,5 +102,24 @@ func (r registryDataAPI) ListProviderAliases(_ context.Context) (map[provider.Ad
 		result[from.Normalize()] = to.Normalize()
 	}
 
+	// Aliases stored for moved providers take precedence and also apply to the built-in aliases pointing to the old
+	// address of a moved provider.
+	stored, err := readAliases[provider.Addr](ctx, r.storageAPI, providerAliasesFile)
+	if err != nil {
+		return nil, err
+	}
+	for from, to := range result {
+		if movedTo, ok := stored[to]; ok {
+			result[from] = movedTo
+		}
+	}
+	for from, to := range stored {
+		result[from] = to
+	}
+
 	return result, nil
 }
+
+func (r registryDataAPI) PutProviderAlias(ctx context.Context, from provider.Addr, to provider.Addr) error {
+	return putAlias(ctx, r.storageAPI, providerAliasesFile, from, to)
+}
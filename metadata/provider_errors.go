@@ -22,3 +22,21 @@ func (m ProviderNotFoundError) Error() string {
 func (m ProviderNotFoundError) Unwrap() error {
 	return m.Cause
 }
+
+type ProviderVersionNotFoundError struct {
+	ProviderAddr provider.Addr
+	Version      provider.VersionNumber
+}
+
+func (m ProviderVersionNotFoundError) Error() string {
+	return "Provider version not found: " + m.ProviderAddr.String() + " " + string(m.Version)
+}
+
+type ProviderVersionAlreadyYankedError struct {
+	ProviderAddr provider.Addr
+	Version      provider.VersionNumber
+}
+
+func (m ProviderVersionAlreadyYankedError) Error() string {
+	return "Provider version already yanked: " + m.ProviderAddr.String() + " " + string(m.Version)
+}
@@ -5,11 +5,16 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/opentofu/libregistry/types/provider"
 )
 
 func (r registryDataAPI) GetAllProviders(ctx context.Context, includeAliases bool) (map[provider.Addr]provider.Metadata, error) {
+	if r.queryAPI != nil {
+		return r.queryAllProviders(ctx, includeAliases)
+	}
 	providerAddrs, err := r.ListProviders(ctx, includeAliases)
 	if err != nil {
 		return nil, err
@@ -23,3 +28,71 @@ func (r registryDataAPI) GetAllProviders(ctx context.Context, includeAliases boo
 	}
 	return result, nil
 }
+
+// queryAllProviders reads all provider metadata files with a single query. Providers affected by an alias are
+// resolved the same way GetProvider resolves them, and the aliases are added if requested.
+func (r registryDataAPI) queryAllProviders(ctx context.Context, includeAliases bool) (map[provider.Addr]provider.Metadata, error) {
+	files, err := r.queryAPI.GetFilesRecursive(ctx, providersDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider files (%w)", err)
+	}
+	namespaceAliases, err := r.ListProviderNamespaceAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	providerAliases, err := r.ListProviderAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[provider.Addr]provider.Metadata, len(files))
+	for p, contents := range files {
+		providerAddr, ok := parseProviderPath(p)
+		if !ok {
+			continue
+		}
+		_, namespaceAliased := namespaceAliases[providerAddr.Namespace]
+		_, providerAliased := providerAliases[providerAddr]
+		if namespaceAliased || providerAliased {
+			// The file is shadowed by the alias target.
+			result[providerAddr], err = r.GetProvider(ctx, providerAddr, true)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var metadata provider.Metadata
+		if err := json.Unmarshal(contents, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse provider metadata file %s (%w)", p, err)
+		}
+		result[providerAddr] = metadata
+	}
+
+	if !includeAliases {
+		return result, nil
+	}
+	// Only the namespaces containing aliases can list additional providers.
+	aliasNamespaces := map[string]struct{}{}
+	for from := range namespaceAliases {
+		aliasNamespaces[from] = struct{}{}
+	}
+	for from := range providerAliases {
+		aliasNamespaces[from.Namespace] = struct{}{}
+	}
+	for namespace := range aliasNamespaces {
+		providerAddrs, err := r.listProvidersByNamespace(ctx, namespace, true, true, namespaceAliases, providerAliases)
+		if err != nil {
+			return nil, err
+		}
+		for _, providerAddr := range providerAddrs {
+			if _, ok := result[providerAddr]; ok {
+				continue
+			}
+			result[providerAddr], err = r.GetProvider(ctx, providerAddr, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
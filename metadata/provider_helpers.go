@@ -6,6 +6,7 @@ package metadata
 import (
 	"context"
 	"path"
+	"strings"
 
 	"github.com/opentofu/libregistry/metadata/storage"
 	"github.com/opentofu/libregistry/types/provider"
@@ -20,3 +21,16 @@ func (r registryDataAPI) getProviderPathCanonical(ctx context.Context, providerA
 	_, providerPath, err := r.getProviderCanonical(ctx, providerAddr)
 	return providerPath, err
 }
+
+// parseProviderPath returns the address of the provider stored in the metadata file at the specified path. The
+// second return value is false if the path does not point to a provider metadata file.
+func parseProviderPath(p storage.Path) (provider.Addr, bool) {
+	parts := strings.Split(string(p), "/")
+	if len(parts) != 4 || parts[0] != providersDirectory || !strings.HasSuffix(parts[3], ".json") {
+		return provider.Addr{}, false
+	}
+	return provider.Addr{
+		Namespace: provider.NormalizeNamespace(parts[2]),
+		Name:      strings.ToLower(strings.TrimSuffix(parts[3], ".json")),
+	}, true
+}
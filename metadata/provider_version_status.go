@@ -0,0 +1,67 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (r registryDataAPI) YankProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, reason string) error {
+	return r.updateProviderVersion(ctx, providerAddr, version, func(ver *provider.Version) error {
+		if ver.Yanked != nil {
+			return &ProviderVersionAlreadyYankedError{
+				ProviderAddr: providerAddr,
+				Version:      ver.Version,
+			}
+		}
+		ver.Yanked = newVersionStatus(reason)
+		return nil
+	})
+}
+
+func (r registryDataAPI) UnyankProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) error {
+	return r.updateProviderVersion(ctx, providerAddr, version, func(ver *provider.Version) error {
+		ver.Yanked = nil
+		return nil
+	})
+}
+
+func (r registryDataAPI) DeprecateProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, reason string) error {
+	return r.updateProviderVersion(ctx, providerAddr, version, func(ver *provider.Version) error {
+		ver.Deprecated = newVersionStatus(reason)
+		return nil
+	})
+}
+
+func (r registryDataAPI) UndeprecateProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber) error {
+	return r.updateProviderVersion(ctx, providerAddr, version, func(ver *provider.Version) error {
+		ver.Deprecated = nil
+		return nil
+	})
+}
+
+func (r registryDataAPI) updateProviderVersion(ctx context.Context, providerAddr provider.Addr, version provider.VersionNumber, update func(ver *provider.Version) error) error {
+	canonicalAddr, err := r.GetProviderCanonicalAddr(ctx, providerAddr)
+	if err != nil {
+		return err
+	}
+	providerMetadata, err := r.GetProvider(ctx, canonicalAddr, false)
+	if err != nil {
+		return err
+	}
+	for i, ver := range providerMetadata.Versions {
+		if ver.Version.Normalize() == version.Normalize() {
+			if err := update(&providerMetadata.Versions[i]); err != nil {
+				return err
+			}
+			return r.PutProvider(ctx, canonicalAddr, providerMetadata)
+		}
+	}
+	return &ProviderVersionNotFoundError{
+		ProviderAddr: providerAddr,
+		Version:      version,
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+// TestQueryAPI checks that the results are the same with and without the query extension of the storage.
+func TestQueryAPI(t *testing.T) {
+	ctx := context.Background()
+	backingStorage := memory.New()
+	walkAPI, err := metadata.New(backingStorage)
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	queries := &countingQueryStorage{API: backingStorage}
+	queryAPI, err := metadata.New(queries)
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+
+	for _, moduleAddr := range []module.Addr{
+		{Namespace: "opentofu", Name: "vpc", TargetSystem: "aws"},
+		{Namespace: "opentofu", Name: "vpc", TargetSystem: "google"},
+		{Namespace: "opentofu", Name: "iam", TargetSystem: "aws"},
+		{Namespace: "other", Name: "vpc", TargetSystem: "aws"},
+	} {
+		if err := walkAPI.PutModule(ctx, moduleAddr, module.Metadata{
+			Versions: []module.Version{{Version: "v1.0.0"}},
+		}); err != nil {
+			t.Fatalf("Failed to put module (%v)", err)
+		}
+	}
+	if err := walkAPI.PutModuleVersionDetails(ctx, module.Addr{Namespace: "opentofu", Name: "vpc", TargetSystem: "aws"}, "v1.0.0", module.VersionDetails{}); err != nil {
+		t.Fatalf("Failed to put module version details (%v)", err)
+	}
+	for _, providerAddr := range []provider.Addr{
+		{Namespace: "opentofu", Name: "aws"},
+		{Namespace: "opentofu", Name: "null"},
+		// Shadowed by the hashicorp -> opentofu namespace alias.
+		{Namespace: "hashicorp", Name: "null"},
+		// Target of the opentofu/aci alias.
+		{Namespace: "CiscoDevNet", Name: "aci"},
+		{Namespace: "example", Name: "test"},
+	} {
+		if err := walkAPI.PutProvider(ctx, providerAddr, provider.Metadata{
+			CustomRepository: providerAddr.String(),
+		}); err != nil {
+			t.Fatalf("Failed to put provider (%v)", err)
+		}
+	}
+
+	t.Run("ListModules", func(t *testing.T) {
+		compareResults(t, queries, walkAPI.ListModules, queryAPI.ListModules, sortModules)
+	})
+	t.Run("ListModulesByNamespace", func(t *testing.T) {
+		list := func(api metadata.API) func(ctx context.Context) ([]module.Addr, error) {
+			return func(ctx context.Context) ([]module.Addr, error) {
+				return api.ListModulesByNamespace(ctx, "OpenTofu")
+			}
+		}
+		compareResults(t, queries, list(walkAPI), list(queryAPI), sortModules)
+	})
+	t.Run("GetAllModules", func(t *testing.T) {
+		compareResults(t, queries, walkAPI.GetAllModules, queryAPI.GetAllModules, nil)
+	})
+	for _, includeAliases := range []bool{false, true} {
+		includeAliases := includeAliases
+		t.Run(fmt.Sprintf("GetAllProviders(%t)", includeAliases), func(t *testing.T) {
+			getAll := func(api metadata.API) func(ctx context.Context) (map[provider.Addr]provider.Metadata, error) {
+				return func(ctx context.Context) (map[provider.Addr]provider.Metadata, error) {
+					return api.GetAllProviders(ctx, includeAliases)
+				}
+			}
+			compareResults(t, queries, getAll(walkAPI), getAll(queryAPI), nil)
+		})
+	}
+}
+
+func compareResults[T any](t *testing.T, queries *countingQueryStorage, walk func(context.Context) (T, error), query func(context.Context) (T, error), normalize func(T)) {
+	t.Helper()
+	ctx := context.Background()
+	expected, err := walk(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the results without queries (%v)", err)
+	}
+	queries.count = 0
+	actual, err := query(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get the results with queries (%v)", err)
+	}
+	if queries.count != 1 {
+		t.Fatalf("Incorrect number of queries: %d", queries.count)
+	}
+	if normalize != nil {
+		normalize(expected)
+		normalize(actual)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Incorrect results with queries:\n%v\n(expected: %v)", actual, expected)
+	}
+}
+
+func sortModules(addrs []module.Addr) {
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+}
+
+// countingQueryStorage implements the query extension by walking the directories of the underlying storage and
+// counts the queries.
+type countingQueryStorage struct {
+	storage.API
+	count int
+}
+
+func (c *countingQueryStorage) ListFilesRecursive(ctx context.Context, directory storage.Path) ([]storage.Path, error) {
+	c.count++
+	return c.walk(ctx, directory)
+}
+
+func (c *countingQueryStorage) GetFilesRecursive(ctx context.Context, directory storage.Path) (map[storage.Path][]byte, error) {
+	c.count++
+	paths, err := c.walk(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[storage.Path][]byte, len(paths))
+	for _, p := range paths {
+		if result[p], err = c.GetFile(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (c *countingQueryStorage) walk(ctx context.Context, directory storage.Path) ([]storage.Path, error) {
+	join := func(name string) storage.Path {
+		if directory == "" {
+			return storage.Path(name)
+		}
+		return directory + "/" + storage.Path(name)
+	}
+	files, err := c.ListFiles(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+	var result []storage.Path
+	for _, file := range files {
+		result = append(result, join(file))
+	}
+	directories, err := c.ListDirectories(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, subdirectory := range directories {
+		subdirectoryFiles, err := c.walk(ctx, join(subdirectory))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, subdirectoryFiles...)
+	}
+	return result, nil
+}
@@ -159,4 +159,61 @@ func TestStorageAPI(t *testing.T, factory func(t *testing.T) API) {
 			t.Fatalf("Fetched already-deleted file (%s)", testFile2)
 		}
 	})
+	t.Run("build-metadata", func(t *testing.T) {
+		// Version numbers with build metadata are used as path elements, so the plus sign must round-trip.
+		const directory = "v1.0.0+build.1"
+		const file = directory + "/v1.0.0+build.1.json"
+		fa := factory(t)
+		if err := fa.PutFile(ctx, file, testFileContents); err != nil {
+			t.Fatalf("Cannot put file %s (%v)", file, err)
+		}
+		directories, err := fa.ListDirectories(ctx, "")
+		if err != nil {
+			t.Fatalf("Cannot list root directory (%v)", err)
+		}
+		if len(directories) != 1 || directories[0] != directory {
+			t.Fatalf("Unexpected directories: %v (want: %s)", directories, directory)
+		}
+		files, err := fa.ListFiles(ctx, directory)
+		if err != nil {
+			t.Fatalf("Cannot list directory %s (%v)", directory, err)
+		}
+		if len(files) != 1 || files[0] != "v1.0.0+build.1.json" {
+			t.Fatalf("Unexpected files in %s: %v", directory, files)
+		}
+		contents, err := fa.GetFile(ctx, file)
+		if err != nil {
+			t.Fatalf("Failed to fetch test file %s (%v)", file, err)
+		}
+		if string(contents) != string(testFileContents) {
+			t.Fatalf("Incorrect file contents: %s", contents)
+		}
+		if err := fa.DeleteFile(ctx, file); err != nil {
+			t.Fatalf("Failed to delete test file %s (%v)", file, err)
+		}
+		if exists, err := fa.FileExists(ctx, file); err != nil || exists {
+			t.Fatalf("Test file %s incorrectly returned as existent (%v)", file, err)
+		}
+	})
+}
+
+// AssertList checks if the list function returns exactly the expected items for the directory, in any order.
+func AssertList(t *testing.T, list func(context.Context, Path) ([]string, error), directory Path, expected ...string) {
+	t.Helper()
+	items, err := list(context.Background(), directory)
+	if err != nil {
+		t.Fatalf("Failed to list %s (%v)", directory, err)
+	}
+	found := map[string]struct{}{}
+	for _, item := range items {
+		found[item] = struct{}{}
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("Incorrect listing of %s: %v (expected: %v)", directory, items, expected)
+	}
+	for _, item := range expected {
+		if _, ok := found[item]; !ok {
+			t.Fatalf("Incorrect listing of %s: %v (expected: %v)", directory, items, expected)
+		}
+	}
 }
@@ -0,0 +1,282 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package cache provides a read-through cache that can be put in front of any storage.API implementation.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// API is a storage.API that caches the file contents, file existence checks and directory listings of the
+// underlying storage in a least recently used cache. Writes through this API invalidate the affected entries. Changes
+// made to the underlying storage by other means are not detected, call Purge after making them.
+type API interface {
+	storage.API
+
+	// Stats returns the cache statistics since the cache was created.
+	Stats() Stats
+	// Purge removes all entries from the cache. The statistics are kept.
+	Purge()
+}
+
+// Stats holds the cache statistics.
+type Stats struct {
+	// Hits is the number of reads answered from the cache.
+	Hits uint64
+	// Misses is the number of reads passed on to the underlying storage.
+	Misses uint64
+	// Evictions is the number of entries removed because the cache was full.
+	Evictions uint64
+	// Entries is the number of entries currently in the cache.
+	Entries int
+}
+
+// New creates a cache in front of the specified storage. If the storage implements storage.QueryAPI, the returned
+// storage also implements it and passes the queries on without caching them.
+func New(backend storage.API, opts ...Opt) (API, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+
+	result := &cache{
+		backend: backend,
+		config:  config,
+		entries: map[entryKey]*list.Element{},
+		lru:     list.New(),
+	}
+	if queryAPI, ok := backend.(storage.QueryAPI); ok {
+		return &queryCache{result, queryAPI}, nil
+	}
+	return result, nil
+}
+
+type entryKind int
+
+const (
+	fileEntry entryKind = iota
+	fileListEntry
+	directoryListEntry
+)
+
+type entryKey struct {
+	kind entryKind
+	path storage.Path
+}
+
+type entry struct {
+	key entryKey
+
+	// exists is true if the file exists. Only used for file entries.
+	exists bool
+	// contents holds the file contents if hasContents is true. A file entry without contents was created by a
+	// FileExists call.
+	contents    []byte
+	hasContents bool
+	// notFound holds the error the underlying storage returned for a missing file.
+	notFound error
+
+	// items holds the result of a directory listing.
+	items []string
+}
+
+type cache struct {
+	backend storage.API
+	config  Config
+
+	lock    sync.Mutex
+	entries map[entryKey]*list.Element
+	// lru holds the entries with the most recently used one at the front.
+	lru *list.List
+	// generation is incremented on every write. Reads started before a write do not store their results, because
+	// they may be outdated.
+	generation uint64
+	stats      Stats
+}
+
+func (c *cache) ListFiles(ctx context.Context, directory storage.Path) ([]string, error) {
+	return c.list(ctx, entryKey{fileListEntry, directory}, c.backend.ListFiles)
+}
+
+func (c *cache) ListDirectories(ctx context.Context, directory storage.Path) ([]string, error) {
+	return c.list(ctx, entryKey{directoryListEntry, directory}, c.backend.ListDirectories)
+}
+
+func (c *cache) list(
+	ctx context.Context,
+	key entryKey,
+	fetch func(ctx context.Context, directory storage.Path) ([]string, error),
+) ([]string, error) {
+	c.lock.Lock()
+	if e := c.get(key); e != nil {
+		c.stats.Hits++
+		items := cloneItems(e.items)
+		c.lock.Unlock()
+		return items, nil
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.lock.Unlock()
+
+	items, err := fetch(ctx, key.path)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, &entry{key: key, items: cloneItems(items)})
+	return items, nil
+}
+
+func (c *cache) GetFile(ctx context.Context, path storage.Path) ([]byte, error) {
+	key := entryKey{fileEntry, path}
+	c.lock.Lock()
+	if e := c.get(key); e != nil && (e.hasContents || !e.exists) {
+		c.stats.Hits++
+		c.lock.Unlock()
+		if !e.exists {
+			if e.notFound != nil {
+				return nil, e.notFound
+			}
+			return nil, &storage.ErrFileNotFound{Path: path}
+		}
+		return bytes.Clone(e.contents), nil
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.lock.Unlock()
+
+	contents, err := c.backend.GetFile(ctx, path)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			c.store(generation, &entry{key: key, notFound: err})
+		}
+		return nil, err
+	}
+	c.store(generation, &entry{key: key, exists: true, contents: bytes.Clone(contents), hasContents: true})
+	return contents, nil
+}
+
+func (c *cache) FileExists(ctx context.Context, path storage.Path) (bool, error) {
+	key := entryKey{fileEntry, path}
+	c.lock.Lock()
+	if e := c.get(key); e != nil {
+		c.stats.Hits++
+		c.lock.Unlock()
+		return e.exists, nil
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.lock.Unlock()
+
+	exists, err := c.backend.FileExists(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	c.store(generation, &entry{key: key, exists: exists})
+	return exists, nil
+}
+
+func (c *cache) PutFile(ctx context.Context, path storage.Path, contents []byte) error {
+	// Invalidate even if the write failed, the underlying storage may have been changed partially.
+	defer c.invalidate(path)
+	return c.backend.PutFile(ctx, path, contents)
+}
+
+func (c *cache) DeleteFile(ctx context.Context, path storage.Path) error {
+	defer c.invalidate(path)
+	return c.backend.DeleteFile(ctx, path)
+}
+
+func (c *cache) Stats() Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	result := c.stats
+	result.Entries = c.lru.Len()
+	return result
+}
+
+func (c *cache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	c.entries = map[entryKey]*list.Element{}
+	c.lru.Init()
+}
+
+// invalidate removes the entries a write to the path may have changed. Writing a file can create or remove
+// directories at any level above it, so the directory listings of all parents are removed.
+func (c *cache) invalidate(path storage.Path) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	c.remove(entryKey{fileEntry, path})
+	c.remove(entryKey{fileListEntry, path.Basename()})
+	directory := path
+	for directory != "" {
+		directory = directory.Basename()
+		c.remove(entryKey{directoryListEntry, directory})
+	}
+}
+
+// get returns the entry and marks it as recently used. The caller must hold the lock.
+func (c *cache) get(key entryKey) *entry {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*entry)
+}
+
+// store adds the entry unless a write happened since the read started, evicting the least recently used entries if
+// the cache is full.
+func (c *cache) store(generation uint64, e *entry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.remove(e.key)
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.config.MaxEntries {
+		c.remove(c.lru.Back().Value.(*entry).key)
+		c.stats.Evictions++
+	}
+}
+
+// remove deletes the entry if present. The caller must hold the lock.
+func (c *cache) remove(key entryKey) {
+	if element, ok := c.entries[key]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+// queryCache is returned for storages implementing storage.QueryAPI, so the metadata API can still use the queries.
+type queryCache struct {
+	*cache
+	queryAPI storage.QueryAPI
+}
+
+func (q *queryCache) ListFilesRecursive(ctx context.Context, directory storage.Path) ([]storage.Path, error) {
+	return q.queryAPI.ListFilesRecursive(ctx, directory)
+}
+
+func (q *queryCache) GetFilesRecursive(ctx context.Context, directory storage.Path) (map[storage.Path][]byte, error) {
+	return q.queryAPI.GetFilesRecursive(ctx, directory)
+}
+
+func cloneItems(items []string) []string {
+	if items == nil {
+		return nil
+	}
+	return append([]string(nil), items...)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/cache"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+)
+
+func TestFileHandling(t *testing.T) {
+	storage.TestStorageAPI(t, func(t *testing.T) storage.API {
+		return newTestCache(t, memory.New())
+	})
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingStorage{API: memory.New()}
+	c := newTestCache(t, backend)
+
+	if err := c.PutFile(ctx, "modules/o/opentofu/vpc/aws.json", []byte("v1")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	for i := 0; i < 3; i++ {
+		assertContents(t, c, "modules/o/opentofu/vpc/aws.json", "v1")
+		if _, err := c.ListDirectories(ctx, "modules/o"); err != nil {
+			t.Fatalf("Failed to list directories (%v)", err)
+		}
+		if exists, err := c.FileExists(ctx, "modules/o/opentofu/vpc/google.json"); err != nil || exists {
+			t.Fatalf("Incorrect existence check result: %t (%v)", exists, err)
+		}
+	}
+	if backend.reads != 3 {
+		t.Fatalf("Incorrect number of reads from the backend: %d", backend.reads)
+	}
+	if stats := c.Stats(); stats.Hits != 6 || stats.Misses != 3 || stats.Entries != 3 {
+		t.Fatalf("Incorrect statistics: %+v", stats)
+	}
+
+	// Missing files are cached too.
+	for i := 0; i < 2; i++ {
+		_, err := c.GetFile(ctx, "modules/o/opentofu/vpc/google.json")
+		var notFound *storage.ErrFileNotFound
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Incorrect error for a missing file (%v)", err)
+		}
+	}
+	if backend.reads != 3 {
+		t.Fatalf("The missing file was read from the backend: %d reads", backend.reads)
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache(t, memory.New())
+
+	if err := c.PutFile(ctx, "modules/o/opentofu/vpc/aws.json", []byte("v1")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	assertContents(t, c, "modules/o/opentofu/vpc/aws.json", "v1")
+	storage.AssertList(t, c.ListFiles, "modules/o/opentofu/vpc", "aws.json")
+	storage.AssertList(t, c.ListDirectories, "modules", "o")
+
+	if err := c.PutFile(ctx, "modules/o/opentofu/vpc/aws.json", []byte("v2")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := c.PutFile(ctx, "modules/o/opentofu/vpc/google.json", []byte("v1")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := c.PutFile(ctx, "modules/t/test/vpc/aws.json", []byte("v1")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	assertContents(t, c, "modules/o/opentofu/vpc/aws.json", "v2")
+	storage.AssertList(t, c.ListFiles, "modules/o/opentofu/vpc", "aws.json", "google.json")
+	storage.AssertList(t, c.ListDirectories, "modules", "o", "t")
+
+	if err := c.DeleteFile(ctx, "modules/o/opentofu/vpc/aws.json"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+	storage.AssertList(t, c.ListFiles, "modules/o/opentofu/vpc", "google.json")
+	if exists, err := c.FileExists(ctx, "modules/o/opentofu/vpc/aws.json"); err != nil || exists {
+		t.Fatalf("The deleted file still exists (%v)", err)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingStorage{API: memory.New()}
+	c := newTestCache(t, backend, cache.WithMaxEntries(2))
+	for _, p := range []storage.Path{"a.json", "b.json", "c.json"} {
+		if err := c.PutFile(ctx, p, []byte(p)); err != nil {
+			t.Fatalf("Failed to put file (%v)", err)
+		}
+	}
+
+	assertContents(t, c, "a.json", "a.json")
+	assertContents(t, c, "b.json", "b.json")
+	// Mark a.json as recently used, so b.json is evicted.
+	assertContents(t, c, "a.json", "a.json")
+	assertContents(t, c, "c.json", "c.json")
+	backend.reads = 0
+	assertContents(t, c, "a.json", "a.json")
+	if backend.reads != 0 {
+		t.Fatalf("The recently used entry was evicted.")
+	}
+	assertContents(t, c, "b.json", "b.json")
+	if backend.reads != 1 {
+		t.Fatalf("The least recently used entry was not evicted.")
+	}
+	if stats := c.Stats(); stats.Evictions != 2 || stats.Entries != 2 {
+		t.Fatalf("Incorrect statistics: %+v", stats)
+	}
+
+	c.Purge()
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("The cache is not empty after purging: %+v", stats)
+	}
+}
+
+func newTestCache(t *testing.T, backend storage.API, opts ...cache.Opt) cache.API {
+	t.Helper()
+	c, err := cache.New(backend, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create cache (%v)", err)
+	}
+	return c
+}
+
+func assertContents(t *testing.T, c cache.API, path storage.Path, expected string) {
+	t.Helper()
+	contents, err := c.GetFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Failed to get file %s (%v)", path, err)
+	}
+	if string(contents) != expected {
+		t.Fatalf("Incorrect contents of %s: %s (expected: %s)", path, contents, expected)
+	}
+}
+
+// countingStorage counts the reads passed on to the underlying storage.
+type countingStorage struct {
+	storage.API
+	reads int
+}
+
+func (c *countingStorage) ListFiles(ctx context.Context, directory storage.Path) ([]string, error) {
+	c.reads++
+	return c.API.ListFiles(ctx, directory)
+}
+
+func (c *countingStorage) ListDirectories(ctx context.Context, directory storage.Path) ([]string, error) {
+	c.reads++
+	return c.API.ListDirectories(ctx, directory)
+}
+
+func (c *countingStorage) GetFile(ctx context.Context, path storage.Path) ([]byte, error) {
+	c.reads++
+	return c.API.GetFile(ctx, path)
+}
+
+func (c *countingStorage) FileExists(ctx context.Context, path storage.Path) (bool, error) {
+	c.reads++
+	return c.API.FileExists(ctx, path)
+}
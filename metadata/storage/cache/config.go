@@ -0,0 +1,36 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package cache
+
+import (
+	"fmt"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the cache.
+type Config struct {
+	// MaxEntries is the number of file contents, file existence checks and directory listings to keep. When the
+	// cache is full, the least recently used entry is evicted. Defaults to 1000.
+	MaxEntries int
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.MaxEntries == 0 {
+		c.MaxEntries = 1000
+	}
+}
+
+// WithMaxEntries sets the number of entries to keep in the cache.
+func WithMaxEntries(maxEntries int) Opt {
+	return func(config *Config) error {
+		if maxEntries <= 0 {
+			return fmt.Errorf("the maximum number of cache entries must be positive (got %d)", maxEntries)
+		}
+		config.MaxEntries = maxEntries
+		return nil
+	}
+}
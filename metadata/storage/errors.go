@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -26,3 +27,10 @@ type ErrFileAlreadyExists struct {
 func (e ErrFileAlreadyExists) Error() string {
 	return fmt.Sprintf("File already exists: %s", e.Path)
 }
+
+// IsNotFound checks if the error is an ErrFileNotFound. Some storages return it as a value, others as a pointer.
+func IsNotFound(err error) bool {
+	var notFoundPtr *ErrFileNotFound
+	var notFound ErrFileNotFound
+	return errors.As(err, &notFoundPtr) || errors.As(err, &notFound)
+}
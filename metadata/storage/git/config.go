@@ -0,0 +1,77 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+import (
+	"fmt"
+
+	"github.com/opentofu/libregistry/logger"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the git storage.
+type Config struct {
+	// Remote is the name of the remote Push pushes to. Defaults to "origin".
+	Remote string
+	// Committer is the committer recorded on the commits. Defaults to the author passed to Commit.
+	Committer *Author
+	// GitPath holds the path to the git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
+	GitPath string
+
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.Remote == "" {
+		c.Remote = "origin"
+	}
+	if c.GitPath == "" {
+		c.GitPath = defaultGitPath
+	}
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// WithRemote sets the name of the remote to push to.
+func WithRemote(remote string) Opt {
+	return func(config *Config) error {
+		if remote == "" {
+			return fmt.Errorf("the remote name must not be empty")
+		}
+		config.Remote = remote
+		return nil
+	}
+}
+
+// WithCommitter sets the committer recorded on the commits, e.g. the bot account running the registry automation.
+func WithCommitter(committer Author) Opt {
+	return func(config *Config) error {
+		if err := committer.Validate(); err != nil {
+			return err
+		}
+		config.Committer = &committer
+		return nil
+	}
+}
+
+// WithGitPath sets the path to the git binary.
+func WithGitPath(path string) Opt {
+	return func(config *Config) error {
+		config.GitPath = path
+		return nil
+	}
+}
+
+// WithLogger sets the logger to use.
+func WithLogger(log logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = log.WithName("Git")
+		return nil
+	}
+}
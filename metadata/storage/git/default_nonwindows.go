@@ -0,0 +1,8 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+
+package git
+
+const defaultGitPath = "git"
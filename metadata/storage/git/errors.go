@@ -0,0 +1,20 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package git
+
+// NothingToCommitError indicates that Commit was called without any changes to commit.
+type NothingToCommitError struct{}
+
+func (n NothingToCommitError) Error() string {
+	return "Nothing to commit"
+}
+
+// InvalidAuthorError indicates that the author or committer of a commit is incomplete.
+type InvalidAuthorError struct {
+	Author Author
+}
+
+func (i InvalidAuthorError) Error() string {
+	return "Invalid author: the name and e-mail address must not be empty (" + i.Author.Name + " <" + i.Author.Email + ">)"
+}
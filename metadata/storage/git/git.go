@@ -0,0 +1,315 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package git provides a storage implementation that works on a local clone of a git repository and records the
+// changes as git commits.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// API is a storage.API that writes to the working tree of a git clone. The files changed using PutFile and
+// DeleteFile are committed together when calling Commit.
+type API interface {
+	storage.API
+
+	// Commit commits all files changed using PutFile and DeleteFile since the last commit as a single commit and
+	// returns the commit hash. Other unstaged changes in the working tree are not committed. Returns a
+	// *NothingToCommitError if no files were changed.
+	Commit(ctx context.Context, message string, author Author) (string, error)
+	// CreateBranch creates a new branch at the current commit and switches to it, for example to prepare a pull
+	// request. Uncommitted changes are kept and will be committed on the new branch.
+	CreateBranch(ctx context.Context, name string) error
+	// Push pushes the current branch to the branch with the same name on the configured remote.
+	Push(ctx context.Context) error
+}
+
+// Author identifies the author or committer of a commit.
+type Author struct {
+	Name  string
+	Email string
+}
+
+// Validate returns an *InvalidAuthorError if the name or the e-mail address is missing.
+func (a Author) Validate() error {
+	if strings.TrimSpace(a.Name) == "" || strings.TrimSpace(a.Email) == "" {
+		return &InvalidAuthorError{a}
+	}
+	return nil
+}
+
+// New creates a storage working on the git clone in the specified directory.
+func New(ctx context.Context, directory string, opts ...Opt) (API, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+
+	result := &storageAPI{
+		directory: directory,
+		config:    config,
+		changed:   map[storage.Path]struct{}{},
+	}
+	if err := result.git(ctx, nil, nil, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("%s is not a git repository (%w)", directory, err)
+	}
+	return result, nil
+}
+
+type storageAPI struct {
+	directory string
+	config    Config
+
+	// lock is held by the write functions and Commit, so a file cannot be written while its path is being staged.
+	lock sync.Mutex
+	// changed holds the paths written or deleted since the last commit.
+	changed map[storage.Path]struct{}
+}
+
+// validatePath rejects invalid paths and paths pointing into the .git directory.
+func validatePath(p storage.Path) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if strings.SplitN(string(p), "/", 2)[0] == ".git" {
+		return fmt.Errorf("invalid path: %s (the .git directory cannot be accessed)", p)
+	}
+	return nil
+}
+
+func (s *storageAPI) fullPath(p storage.Path) string {
+	return filepath.Join(s.directory, filepath.FromSlash(string(p)))
+}
+
+func (s *storageAPI) ListFiles(_ context.Context, directory storage.Path) ([]string, error) {
+	return s.list(directory, false)
+}
+
+func (s *storageAPI) ListDirectories(_ context.Context, directory storage.Path) ([]string, error) {
+	return s.list(directory, true)
+}
+
+func (s *storageAPI) list(directory storage.Path, directories bool) ([]string, error) {
+	if err := validatePath(directory); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(s.fullPath(directory))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list directory %s (%w)", directory, err)
+	}
+	var result []string
+	for _, entry := range entries {
+		if entry.IsDir() != directories || (directory == "" && entry.Name() == ".git") {
+			continue
+		}
+		result = append(result, entry.Name())
+	}
+	return result, nil
+}
+
+func (s *storageAPI) PutFile(_ context.Context, filePath storage.Path, contents []byte) error {
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fullPath := s.fullPath(filePath)
+	if info, err := os.Stat(fullPath); err == nil && info.IsDir() {
+		return &storage.ErrFileAlreadyExists{
+			Path: filePath,
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create base directory for %s (%w)", filePath, err)
+	}
+	if err := os.WriteFile(fullPath, contents, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s (%w)", filePath, err)
+	}
+	s.markChanged(filePath)
+	return nil
+}
+
+func (s *storageAPI) GetFile(_ context.Context, filePath storage.Path) ([]byte, error) {
+	if err := validatePath(filePath); err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(s.fullPath(filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &storage.ErrFileNotFound{
+				Path: filePath,
+			}
+		}
+		return nil, fmt.Errorf("failed to read file %s (%w)", filePath, err)
+	}
+	return contents, nil
+}
+
+func (s *storageAPI) FileExists(_ context.Context, filePath storage.Path) (bool, error) {
+	if err := validatePath(filePath); err != nil {
+		return false, err
+	}
+	info, err := os.Stat(s.fullPath(filePath))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat file %s (%w)", filePath, err)
+	}
+	return !info.IsDir(), nil
+}
+
+func (s *storageAPI) DeleteFile(_ context.Context, filePath storage.Path) error {
+	if err := validatePath(filePath); err != nil {
+		return err
+	}
+	if filePath == "" {
+		return &storage.ErrFileNotFound{
+			Path: filePath,
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := os.Remove(s.fullPath(filePath)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove file %s (%w)", filePath, err)
+	}
+	// Git does not track directories, so empty directories are removed to keep the listings consistent with a fresh
+	// clone.
+	for dir := filePath.Basename(); dir != ""; dir = dir.Basename() {
+		if err := os.Remove(s.fullPath(dir)); err != nil {
+			break
+		}
+	}
+	s.markChanged(filePath)
+	return nil
+}
+
+// markChanged records the path for the next commit. The caller must hold the lock.
+func (s *storageAPI) markChanged(filePath storage.Path) {
+	s.changed[filePath] = struct{}{}
+}
+
+func (s *storageAPI) Commit(ctx context.Context, message string, author Author) (string, error) {
+	if err := author.Validate(); err != nil {
+		return "", err
+	}
+	committer := author
+	if s.config.Committer != nil {
+		committer = *s.config.Committer
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.changed) == 0 {
+		return "", &NothingToCommitError{}
+	}
+
+	var existing, deleted []string
+	for filePath := range s.changed {
+		if _, err := os.Stat(s.fullPath(filePath)); err == nil {
+			existing = append(existing, string(filePath))
+		} else {
+			deleted = append(deleted, string(filePath))
+		}
+	}
+	sort.Strings(existing)
+	sort.Strings(deleted)
+	if len(existing) > 0 {
+		if err := s.git(ctx, nil, nil, append([]string{"add", "--"}, existing...)...); err != nil {
+			return "", fmt.Errorf("failed to stage changed files (%w)", err)
+		}
+	}
+	if len(deleted) > 0 {
+		if err := s.git(ctx, nil, nil, append([]string{"rm", "--cached", "--ignore-unmatch", "--quiet", "--"}, deleted...)...); err != nil {
+			return "", fmt.Errorf("failed to stage deleted files (%w)", err)
+		}
+	}
+	// Files written with their previous contents leave nothing to commit.
+	if err := s.git(ctx, nil, nil, "diff", "--cached", "--quiet"); err == nil {
+		s.changed = map[storage.Path]struct{}{}
+		return "", &NothingToCommitError{}
+	}
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + author.Name,
+		"GIT_AUTHOR_EMAIL=" + author.Email,
+		"GIT_COMMITTER_NAME=" + committer.Name,
+		"GIT_COMMITTER_EMAIL=" + committer.Email,
+	}
+	if err := s.git(ctx, env, nil, "commit", "--quiet", "--no-verify", "--message", message); err != nil {
+		return "", fmt.Errorf("failed to commit changes (%w)", err)
+	}
+	s.changed = map[storage.Path]struct{}{}
+
+	hash := &bytes.Buffer{}
+	if err := s.git(ctx, nil, hash, "rev-parse", "HEAD"); err != nil {
+		return "", fmt.Errorf("failed to determine the commit hash (%w)", err)
+	}
+	return strings.TrimSpace(hash.String()), nil
+}
+
+func (s *storageAPI) CreateBranch(ctx context.Context, name string) error {
+	if err := s.git(ctx, nil, nil, "check-ref-format", "--branch", name); err != nil {
+		return fmt.Errorf("invalid branch name: %s (%w)", name, err)
+	}
+	if err := s.git(ctx, nil, nil, "checkout", "--quiet", "-b", name); err != nil {
+		return fmt.Errorf("failed to create branch %s (%w)", name, err)
+	}
+	return nil
+}
+
+func (s *storageAPI) Push(ctx context.Context) error {
+	if err := s.git(ctx, nil, nil, "push", "--quiet", s.config.Remote, "HEAD"); err != nil {
+		return fmt.Errorf("failed to push to %s (%w)", s.config.Remote, err)
+	}
+	return nil
+}
+
+// git runs a git command in the working tree with the additional environment variables. The standard output is
+// written to stdout if set, otherwise it is logged.
+func (s *storageAPI) git(ctx context.Context, env []string, stdout *bytes.Buffer, params ...string) error {
+	cmd := exec.CommandContext(ctx, s.config.GitPath, params...)
+	commandString := strings.Join(append([]string{s.config.GitPath}, params...), " ")
+	logger.LogTrace(ctx, s.config.Logger, "Running "+commandString)
+	cmd.Dir = s.directory
+	// Keep the environment, so SSH agents, proxies and the credential helpers of the user keep working for Push, but
+	// fail instead of waiting for a password prompt.
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	if stdout != nil {
+		cmd.Stdout = stdout
+	} else {
+		cmd.Stdout = logger.NewWriter(ctx, s.config.Logger, logger.LevelDebug, commandString+": ")
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return fmt.Errorf("%s failed: %s (%w)", commandString, output, err)
+		}
+		return fmt.Errorf("%s failed (%w)", commandString, err)
+	}
+	return nil
+}
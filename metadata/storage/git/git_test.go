@@ -0,0 +1,206 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package git_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/git"
+)
+
+var testAuthor = git.Author{Name: "Registry Bot", Email: "bot@example.com"}
+
+func TestFileHandling(t *testing.T) {
+	storage.TestStorageAPI(t, func(t *testing.T) storage.API {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "--quiet")
+		api, err := git.New(context.Background(), dir)
+		if err != nil {
+			t.Fatalf("Failed to create git storage (%v)", err)
+		}
+		return api
+	})
+}
+
+func TestCommitAndPush(t *testing.T) {
+	ctx := context.Background()
+	remote, clone := createRepository(t)
+	api, err := git.New(ctx, clone)
+	if err != nil {
+		t.Fatalf("Failed to create git storage (%v)", err)
+	}
+
+	if _, err := api.Commit(ctx, "Empty commit", testAuthor); err == nil {
+		t.Fatalf("Committing without changes did not fail.")
+	} else {
+		var nothingToCommit *git.NothingToCommitError
+		if !errors.As(err, &nothingToCommit) {
+			t.Fatalf("Incorrect error when committing without changes (%v)", err)
+		}
+	}
+
+	if err := api.PutFile(ctx, "modules/t/test/aws/iam.json", []byte("{}")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := api.PutFile(ctx, "modules/t/test/aws/vpc.json", []byte("{}")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := api.DeleteFile(ctx, "README.md"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+	hash, err := api.Commit(ctx, "Add test modules", testAuthor)
+	if err != nil {
+		t.Fatalf("Failed to commit (%v)", err)
+	}
+	if err := api.Push(ctx); err != nil {
+		t.Fatalf("Failed to push (%v)", err)
+	}
+
+	if remoteHash := runGit(t, remote, "rev-parse", "main"); remoteHash != hash {
+		t.Fatalf("The remote is at %s instead of %s.", remoteHash, hash)
+	}
+	if log := runGit(t, remote, "log", "-1", "--format=%an <%ae>: %s", "main"); log != "Registry Bot <bot@example.com>: Add test modules" {
+		t.Fatalf("Incorrect commit: %s", log)
+	}
+	files := runGit(t, remote, "ls-tree", "-r", "--name-only", "main")
+	if files != "modules/t/test/aws/iam.json\nmodules/t/test/aws/vpc.json" {
+		t.Fatalf("Incorrect files in the pushed commit:\n%s", files)
+	}
+}
+
+func TestCreateBranch(t *testing.T) {
+	ctx := context.Background()
+	remote, clone := createRepository(t)
+	api, err := git.New(ctx, clone, git.WithCommitter(git.Author{Name: "Committer", Email: "committer@example.com"}))
+	if err != nil {
+		t.Fatalf("Failed to create git storage (%v)", err)
+	}
+
+	if err := api.PutFile(ctx, "test.json", []byte("{}")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := api.CreateBranch(ctx, "update/test"); err != nil {
+		t.Fatalf("Failed to create branch (%v)", err)
+	}
+	if _, err := api.Commit(ctx, "Update test", testAuthor); err != nil {
+		t.Fatalf("Failed to commit (%v)", err)
+	}
+	if err := api.Push(ctx); err != nil {
+		t.Fatalf("Failed to push (%v)", err)
+	}
+
+	if log := runGit(t, remote, "log", "-1", "--format=%an/%cn: %s", "update/test"); log != "Registry Bot/Committer: Update test" {
+		t.Fatalf("Incorrect commit on the branch: %s", log)
+	}
+	if log := runGit(t, remote, "log", "-1", "--format=%s", "main"); log != "Initial commit" {
+		t.Fatalf("The main branch was modified: %s", log)
+	}
+	if err := api.CreateBranch(ctx, "invalid..branch"); err == nil {
+		t.Fatalf("Creating a branch with an invalid name did not fail.")
+	}
+}
+
+func TestPushWithCredentials(t *testing.T) {
+	ctx := context.Background()
+	remote, clone := createRepository(t)
+	runGit(t, remote, "config", "http.receivepack", "true")
+
+	execPath := runGit(t, "", "--exec-path")
+	backend := &cgi.Handler{
+		Path: filepath.Join(execPath, "git-http-backend"),
+		Env: []string{
+			"GIT_PROJECT_ROOT=" + filepath.Dir(remote),
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); !ok || username != "registry" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, req)
+	}))
+	t.Cleanup(server.Close)
+	runGit(t, clone, "remote", "set-url", "origin", server.URL+"/"+filepath.Base(remote))
+
+	// Use a global git config of our own, so the credential helpers of the machine running the test do not interfere.
+	globalConfig := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(globalConfig, nil, 0644); err != nil {
+		t.Fatalf("Failed to write git config (%v)", err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+
+	api, err := git.New(ctx, clone)
+	if err != nil {
+		t.Fatalf("Failed to create git storage (%v)", err)
+	}
+	if err := api.PutFile(ctx, "test.json", []byte("{}")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	hash, err := api.Commit(ctx, "Add test file", testAuthor)
+	if err != nil {
+		t.Fatalf("Failed to commit (%v)", err)
+	}
+
+	// Without credentials, the push must fail instead of waiting for a password prompt.
+	if err := api.Push(ctx); err == nil {
+		t.Fatalf("Pushing without credentials did not fail.")
+	}
+
+	// The credential helper configured by the user must be used.
+	runGit(t, "", "config", "--file", globalConfig, "credential.helper", "!f() { echo username=registry; echo password=secret; }; f")
+	if err := api.Push(ctx); err != nil {
+		t.Fatalf("Failed to push with the configured credential helper (%v)", err)
+	}
+	if remoteHash := runGit(t, remote, "rev-parse", "main"); remoteHash != hash {
+		t.Fatalf("The remote is at %s instead of %s.", remoteHash, hash)
+	}
+}
+
+// createRepository creates a bare remote repository with an initial commit on the main branch and a clone of it.
+func createRepository(t *testing.T) (string, string) {
+	t.Helper()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, "", "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	runGit(t, "", "init", "--quiet", "--initial-branch=main", clone)
+	initial, err := git.New(context.Background(), clone)
+	if err != nil {
+		t.Fatalf("Failed to create git storage (%v)", err)
+	}
+	if err := initial.PutFile(context.Background(), "README.md", []byte("Registry data")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if _, err := initial.Commit(context.Background(), "Initial commit", testAuthor); err != nil {
+		t.Fatalf("Failed to create the initial commit (%v)", err)
+	}
+	runGit(t, clone, "remote", "add", "origin", remote)
+	runGit(t, clone, "push", "--quiet", "origin", "main")
+	return remote, clone
+}
+
+func runGit(t *testing.T, dir string, params ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", params...)
+	cmd.Dir = dir
+	output := &bytes.Buffer{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to run git %s (%v)\n%s", strings.Join(params, " "), err, output.String())
+	}
+	return strings.TrimSpace(output.String())
+}
@@ -0,0 +1,288 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package overlay provides a copy-on-write storage that stacks a writable storage over a read-only one. This lets
+// you run jobs against a production registry checkout and review or discard their changes without copying the data.
+package overlay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/libregistry/internal/mapkeys"
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// whiteoutPrefix marks the files in the upper layer that record the deletion of a file in the lower layer, the same
+// way OverlayFS does. Paths starting with this prefix cannot be stored in the overlay.
+const whiteoutPrefix = ".wh."
+
+// API is a storage.API combining a read-only lower layer with a writable upper layer. Reads return the file from the
+// upper layer if present and fall through to the lower layer otherwise. Writes only go to the upper layer. Deleting
+// a file present in the lower layer records a whiteout in the upper layer that hides the file.
+type API interface {
+	storage.API
+
+	// Changes returns the files written and deleted in the upper layer, sorted by path.
+	Changes(ctx context.Context) ([]Change, error)
+}
+
+// Change is a single file changed in the upper layer.
+type Change struct {
+	Path storage.Path
+	// Deleted is true if the file was deleted from the lower layer.
+	Deleted bool
+	// Contents holds the new file contents if the file was not deleted.
+	Contents []byte
+}
+
+// New creates an overlay of the upper storage over the lower storage. The lower storage is never modified. The upper
+// storage may already contain changes from a previous run.
+func New(upper storage.API, lower storage.API) API {
+	return &overlay{
+		upper: upper,
+		lower: lower,
+	}
+}
+
+// Apply writes the changes to the target storage, for example to merge the upper layer into the lower layer.
+func Apply(ctx context.Context, target storage.API, changes []Change) error {
+	for _, change := range changes {
+		if change.Deleted {
+			if err := target.DeleteFile(ctx, change.Path); err != nil {
+				return fmt.Errorf("failed to delete file %s (%w)", change.Path, err)
+			}
+			continue
+		}
+		if err := target.PutFile(ctx, change.Path, change.Contents); err != nil {
+			return fmt.Errorf("failed to write file %s (%w)", change.Path, err)
+		}
+	}
+	return nil
+}
+
+type overlay struct {
+	upper storage.API
+	lower storage.API
+}
+
+func (o *overlay) ListFiles(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := validatePath(directory); err != nil {
+		return nil, err
+	}
+	upperFiles, err := o.upper.ListFiles(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in the upper layer in %s (%w)", directory, err)
+	}
+	lowerFiles, err := o.lower.ListFiles(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in the lower layer in %s (%w)", directory, err)
+	}
+
+	result := map[string]struct{}{}
+	for _, file := range lowerFiles {
+		result[file] = struct{}{}
+	}
+	for _, file := range upperFiles {
+		if strings.HasPrefix(file, whiteoutPrefix) {
+			delete(result, strings.TrimPrefix(file, whiteoutPrefix))
+		}
+	}
+	for _, file := range upperFiles {
+		if !strings.HasPrefix(file, whiteoutPrefix) {
+			result[file] = struct{}{}
+		}
+	}
+	return mapkeys.Sorted(result), nil
+}
+
+func (o *overlay) ListDirectories(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := validatePath(directory); err != nil {
+		return nil, err
+	}
+	upperDirectories, err := o.upper.ListDirectories(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directories in the upper layer in %s (%w)", directory, err)
+	}
+	lowerDirectories, err := o.lower.ListDirectories(ctx, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directories in the lower layer in %s (%w)", directory, err)
+	}
+
+	result := map[string]struct{}{}
+	for _, subdirectory := range lowerDirectories {
+		result[subdirectory] = struct{}{}
+	}
+	for _, subdirectory := range upperDirectories {
+		if _, inLower := result[subdirectory]; !inLower {
+			result[subdirectory] = struct{}{}
+			continue
+		}
+		// The upper layer may hold whiteouts for all files of the lower directory.
+		visible, err := o.hasFiles(ctx, joinPath(directory, subdirectory))
+		if err != nil {
+			return nil, err
+		}
+		if !visible {
+			delete(result, subdirectory)
+		}
+	}
+	return mapkeys.Sorted(result), nil
+}
+
+// hasFiles checks if the merged directory or any of its subdirectories contains a file.
+func (o *overlay) hasFiles(ctx context.Context, directory storage.Path) (bool, error) {
+	files, err := o.ListFiles(ctx, directory)
+	if err != nil {
+		return false, err
+	}
+	if len(files) > 0 {
+		return true, nil
+	}
+	subdirectories, err := o.ListDirectories(ctx, directory)
+	if err != nil {
+		return false, err
+	}
+	return len(subdirectories) > 0, nil
+}
+
+func (o *overlay) PutFile(ctx context.Context, path storage.Path, contents []byte) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	if err := o.upper.PutFile(ctx, path, contents); err != nil {
+		return err
+	}
+	if err := o.upper.DeleteFile(ctx, whiteoutPath(path)); err != nil {
+		return fmt.Errorf("failed to remove the whiteout for %s (%w)", path, err)
+	}
+	return nil
+}
+
+func (o *overlay) GetFile(ctx context.Context, path storage.Path) ([]byte, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+	contents, err := o.upper.GetFile(ctx, path)
+	if err == nil || !storage.IsNotFound(err) {
+		return contents, err
+	}
+	deleted, err := o.upper.FileExists(ctx, whiteoutPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the whiteout for %s (%w)", path, err)
+	}
+	if deleted {
+		return nil, &storage.ErrFileNotFound{Path: path}
+	}
+	return o.lower.GetFile(ctx, path)
+}
+
+func (o *overlay) FileExists(ctx context.Context, path storage.Path) (bool, error) {
+	if err := validatePath(path); err != nil {
+		return false, err
+	}
+	exists, err := o.upper.FileExists(ctx, path)
+	if err != nil || exists {
+		return exists, err
+	}
+	deleted, err := o.upper.FileExists(ctx, whiteoutPath(path))
+	if err != nil {
+		return false, fmt.Errorf("failed to check the whiteout for %s (%w)", path, err)
+	}
+	if deleted {
+		return false, nil
+	}
+	return o.lower.FileExists(ctx, path)
+}
+
+func (o *overlay) DeleteFile(ctx context.Context, path storage.Path) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	if err := o.upper.DeleteFile(ctx, path); err != nil {
+		return err
+	}
+	inLower, err := o.lower.FileExists(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to check file %s in the lower layer (%w)", path, err)
+	}
+	if !inLower {
+		return nil
+	}
+	if err := o.upper.PutFile(ctx, whiteoutPath(path), []byte{}); err != nil {
+		return fmt.Errorf("failed to write the whiteout for %s (%w)", path, err)
+	}
+	return nil
+}
+
+func (o *overlay) Changes(ctx context.Context) ([]Change, error) {
+	var result []Change
+	if err := o.collectChanges(ctx, "", &result); err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+func (o *overlay) collectChanges(ctx context.Context, directory storage.Path, result *[]Change) error {
+	files, err := o.upper.ListFiles(ctx, directory)
+	if err != nil {
+		return fmt.Errorf("failed to list files in the upper layer in %s (%w)", directory, err)
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file, whiteoutPrefix) {
+			*result = append(*result, Change{
+				Path:    joinPath(directory, strings.TrimPrefix(file, whiteoutPrefix)),
+				Deleted: true,
+			})
+			continue
+		}
+		path := joinPath(directory, file)
+		contents, err := o.upper.GetFile(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s from the upper layer (%w)", path, err)
+		}
+		*result = append(*result, Change{
+			Path:     path,
+			Contents: contents,
+		})
+	}
+	directories, err := o.upper.ListDirectories(ctx, directory)
+	if err != nil {
+		return fmt.Errorf("failed to list directories in the upper layer in %s (%w)", directory, err)
+	}
+	for _, subdirectory := range directories {
+		if err := o.collectChanges(ctx, joinPath(directory, subdirectory), result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePath rejects invalid paths and paths that would be mistaken for whiteouts.
+func validatePath(p storage.Path) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	for _, part := range strings.Split(string(p), "/") {
+		if strings.HasPrefix(part, whiteoutPrefix) {
+			return fmt.Errorf("invalid path: %s (names starting with %s are reserved for whiteouts)", p, whiteoutPrefix)
+		}
+	}
+	return nil
+}
+
+func whiteoutPath(p storage.Path) storage.Path {
+	return joinPath(p.Basename(), whiteoutPrefix+p.Filename())
+}
+
+func joinPath(directory storage.Path, name string) storage.Path {
+	if directory == "" {
+		return storage.Path(name)
+	}
+	return directory + "/" + storage.Path(name)
+}
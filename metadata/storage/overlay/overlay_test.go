@@ -0,0 +1,128 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package overlay_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/metadata/storage/overlay"
+)
+
+func TestFileHandling(t *testing.T) {
+	storage.TestStorageAPI(t, func(t *testing.T) storage.API {
+		return overlay.New(memory.New(), memory.New())
+	})
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	ctx := context.Background()
+	lower := memory.New()
+	for _, p := range []storage.Path{
+		"modules/o/opentofu/vpc/aws.json",
+		"modules/o/opentofu/vpc/google.json",
+		"modules/t/test/iam/aws.json",
+	} {
+		if err := lower.PutFile(ctx, p, []byte("lower")); err != nil {
+			t.Fatalf("Failed to put file (%v)", err)
+		}
+	}
+	upper := memory.New()
+	api := overlay.New(upper, lower)
+
+	// Reads fall through to the lower layer.
+	assertContents(t, api, "modules/o/opentofu/vpc/aws.json", "lower")
+	storage.AssertList(t, api.ListDirectories, "modules", "o", "t")
+
+	if err := api.PutFile(ctx, "modules/o/opentofu/vpc/aws.json", []byte("upper")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := api.PutFile(ctx, "modules/o/opentofu/vpc/azurerm.json", []byte("upper")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	if err := api.DeleteFile(ctx, "modules/o/opentofu/vpc/google.json"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+	if err := api.DeleteFile(ctx, "modules/t/test/iam/aws.json"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+
+	assertContents(t, api, "modules/o/opentofu/vpc/aws.json", "upper")
+	assertContents(t, lower, "modules/o/opentofu/vpc/aws.json", "lower")
+	assertContents(t, lower, "modules/o/opentofu/vpc/google.json", "lower")
+	if _, err := api.GetFile(ctx, "modules/o/opentofu/vpc/google.json"); err == nil {
+		t.Fatalf("The deleted file is still readable.")
+	} else {
+		var notFound *storage.ErrFileNotFound
+		if !errors.As(err, &notFound) {
+			t.Fatalf("Incorrect error for a deleted file (%v)", err)
+		}
+	}
+	if exists, err := api.FileExists(ctx, "modules/o/opentofu/vpc/google.json"); err != nil || exists {
+		t.Fatalf("The deleted file still exists (%v)", err)
+	}
+	storage.AssertList(t, api.ListFiles, "modules/o/opentofu/vpc", "aws.json", "azurerm.json")
+	// The whole test namespace was deleted.
+	storage.AssertList(t, api.ListDirectories, "modules", "o")
+
+	// Writing a deleted file again removes the whiteout.
+	if err := api.PutFile(ctx, "modules/o/opentofu/vpc/google.json", []byte("upper")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+	assertContents(t, api, "modules/o/opentofu/vpc/google.json", "upper")
+	if err := api.DeleteFile(ctx, "modules/o/opentofu/vpc/google.json"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+
+	changes, err := api.Changes(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list changes (%v)", err)
+	}
+	var described []string
+	for _, change := range changes {
+		if change.Deleted {
+			described = append(described, "-"+string(change.Path))
+		} else {
+			described = append(described, "+"+string(change.Path)+"="+string(change.Contents))
+		}
+	}
+	expected := []string{
+		"+modules/o/opentofu/vpc/aws.json=upper",
+		"+modules/o/opentofu/vpc/azurerm.json=upper",
+		"-modules/o/opentofu/vpc/google.json",
+		"-modules/t/test/iam/aws.json",
+	}
+	if strings.Join(described, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Incorrect changes: %v (expected: %v)", described, expected)
+	}
+
+	// Applying the changes to the lower layer produces the merged view.
+	if err := overlay.Apply(ctx, lower, changes); err != nil {
+		t.Fatalf("Failed to apply changes (%v)", err)
+	}
+	assertContents(t, lower, "modules/o/opentofu/vpc/aws.json", "upper")
+	storage.AssertList(t, lower.ListFiles, "modules/o/opentofu/vpc", "aws.json", "azurerm.json")
+}
+
+func TestWhiteoutNamesRejected(t *testing.T) {
+	api := overlay.New(memory.New(), memory.New())
+	if err := api.PutFile(context.Background(), "modules/.wh.test.json", []byte("{}")); err == nil {
+		t.Fatalf("Writing a file with a whiteout name did not fail.")
+	}
+}
+
+func assertContents(t *testing.T, api storage.API, path storage.Path, expected string) {
+	t.Helper()
+	contents, err := api.GetFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Failed to get file %s (%v)", path, err)
+	}
+	if string(contents) != expected {
+		t.Fatalf("Incorrect contents of %s: %s (expected: %s)", path, contents, expected)
+	}
+}
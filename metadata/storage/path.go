@@ -9,7 +9,9 @@ import (
 	"strings"
 )
 
-var pathRe = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+// pathRe matches a single path element. The plus sign is allowed because version numbers with SemVer build metadata,
+// such as v1.0.0+build.1, are used as file and directory names.
+var pathRe = regexp.MustCompile(`^[a-zA-Z0-9._+-]+$`)
 
 // Path is a reference to a directory or file in the storage.
 type Path string
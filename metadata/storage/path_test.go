@@ -0,0 +1,23 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+func TestPathValidate(t *testing.T) {
+	for _, p := range []storage.Path{"", "modules", "module-details/o/opentofu/vpc/aws/v1.0.0+build.1.json"} {
+		if err := p.Validate(); err != nil {
+			t.Fatalf("Failed to validate valid path %s (%v)", p, err)
+		}
+	}
+	for _, p := range []storage.Path{"/modules", "modules/", "modules//vpc", "modules/v1 0", "modules/a%2Fb"} {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("Validating invalid path %s did not fail", p)
+		}
+	}
+}
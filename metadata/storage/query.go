@@ -0,0 +1,21 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package storage
+
+import (
+	"context"
+)
+
+// QueryAPI is an optional extension of API for storages that can search the whole file tree at once, such as
+// databases. The metadata API uses these functions instead of walking the directories one level at a time if the
+// storage implements them. Like the functions of API, they must include changes that have not been committed yet.
+type QueryAPI interface {
+	API
+
+	// ListFilesRecursive returns the full paths of all files in the specified directory and its subdirectories.
+	ListFilesRecursive(ctx context.Context, directory Path) ([]Path, error)
+	// GetFilesRecursive returns the contents of all files in the specified directory and its subdirectories, keyed
+	// by the full path of the file.
+	GetFilesRecursive(ctx context.Context, directory Path) (map[Path][]byte, error)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the S3 storage.
+type Config struct {
+	// Endpoint is the base URL of the S3-compatible service, e.g. https://s3.eu-central-1.amazonaws.com or
+	// http://localhost:9000. Defaults to the AWS endpoint of the region.
+	Endpoint string
+	// Region is the region used for signing requests. Defaults to "us-east-1".
+	Region string
+	// Bucket is the name of the bucket to store the files in. Required.
+	Bucket string
+	// Prefix is prepended to all object keys, allowing the metadata to share a bucket with other data. Defaults to
+	// no prefix.
+	Prefix string
+	// PathStyle addresses the bucket in the URL path (https://endpoint/bucket/key) instead of the host name
+	// (https://bucket.endpoint/key). Most S3-compatible services other than AWS need this. Defaults to false.
+	PathStyle bool
+	// Credentials holds the access keys to sign the requests with. Defaults to sending unsigned requests.
+	Credentials *Credentials
+
+	// HTTPClient holds the HTTP client to use for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// TimeSource returns the current time for signing requests. Defaults to time.Now.
+	TimeSource func() time.Time
+	// Logger holds the logger to write any logs to.
+	Logger logger.Logger
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = "https://s3." + c.Region + ".amazonaws.com"
+	}
+	if c.Prefix != "" && !strings.HasSuffix(c.Prefix, "/") {
+		c.Prefix += "/"
+	}
+	if c.HTTPClient == nil {
+		c.HTTPClient = http.DefaultClient
+	}
+	if c.TimeSource == nil {
+		c.TimeSource = time.Now
+	}
+	if c.Logger == nil {
+		c.Logger = logger.NewNoopLogger()
+	}
+}
+
+// Validate checks the configuration after the defaults have been applied.
+func (c *Config) Validate() error {
+	if c.Bucket == "" {
+		return fmt.Errorf("the bucket name must not be empty")
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid S3 endpoint %s (%w)", c.Endpoint, err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("invalid S3 endpoint %s (must be an http or https URL)", c.Endpoint)
+	}
+	return nil
+}
+
+// WithEndpoint sets the base URL of the S3-compatible service.
+func WithEndpoint(endpoint string) Opt {
+	return func(config *Config) error {
+		config.Endpoint = strings.TrimSuffix(endpoint, "/")
+		return nil
+	}
+}
+
+// WithRegion sets the region used for signing requests.
+func WithRegion(region string) Opt {
+	return func(config *Config) error {
+		config.Region = region
+		return nil
+	}
+}
+
+// WithBucket sets the bucket to store the files in.
+func WithBucket(bucket string) Opt {
+	return func(config *Config) error {
+		config.Bucket = bucket
+		return nil
+	}
+}
+
+// WithPrefix sets the prefix prepended to all object keys.
+func WithPrefix(prefix string) Opt {
+	return func(config *Config) error {
+		config.Prefix = strings.Trim(prefix, "/")
+		return nil
+	}
+}
+
+// WithPathStyle enables addressing the bucket in the URL path instead of the host name.
+func WithPathStyle(pathStyle bool) Opt {
+	return func(config *Config) error {
+		config.PathStyle = pathStyle
+		return nil
+	}
+}
+
+// WithCredentials sets the access keys to sign the requests with.
+func WithCredentials(credentials Credentials) Opt {
+	return func(config *Config) error {
+		if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+			return fmt.Errorf("the access key ID and the secret access key must not be empty")
+		}
+		config.Credentials = &credentials
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client to use for requests.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(config *Config) error {
+		config.HTTPClient = client
+		return nil
+	}
+}
+
+// WithTimeSource sets the function returning the current time for signing requests.
+func WithTimeSource(timeSource func() time.Time) Opt {
+	return func(config *Config) error {
+		config.TimeSource = timeSource
+		return nil
+	}
+}
+
+// WithLogger sets the logger to use.
+func WithLogger(log logger.Logger) Opt {
+	return func(config *Config) error {
+		config.Logger = log.WithName("S3")
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package s3
+
+import (
+	"strconv"
+)
+
+// RequestFailedError indicates that the S3 service returned an unexpected status code. Code and Message are taken
+// from the error response, if any.
+type RequestFailedError struct {
+	Method     string
+	Key        string
+	StatusCode int
+	Code       string
+	Message    string
+	Cause      error
+}
+
+func (r RequestFailedError) Error() string {
+	result := "S3 request " + r.Method + " " + r.Key + " failed"
+	if r.StatusCode != 0 {
+		result += " with status code " + strconv.Itoa(r.StatusCode)
+	}
+	if r.Code != "" {
+		result += ": " + r.Code
+		if r.Message != "" {
+			result += " (" + r.Message + ")"
+		}
+	}
+	if r.Cause != nil {
+		result += " (" + r.Cause.Error() + ")"
+	}
+	return result
+}
+
+func (r RequestFailedError) Unwrap() error {
+	return r.Cause
+}
@@ -0,0 +1,484 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package s3 provides a storage implementation for Amazon S3 and S3-compatible object storages, such as MinIO or
+// Cloudflare R2.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opentofu/libregistry/internal/mapkeys"
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// API is a storage.API that stores the files as objects in an S3 bucket. Object storages have no directories, so
+// directories are emulated using the "/" delimiter in the object keys. Files changed using PutFile and DeleteFile are
+// buffered in memory and only uploaded when calling Commit.
+type API interface {
+	storage.API
+
+	// Commit uploads the files written and deletes the files removed since the last commit. S3 has no transactions,
+	// so if a request fails, the changes made before the failure stay in the bucket and the remaining changes stay
+	// buffered. Calling Commit again retries them.
+	Commit(ctx context.Context) error
+}
+
+// New creates a storage for the configured bucket.
+func New(opts ...Opt) (API, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %s (%w)", config.Endpoint, err)
+	}
+	return &storageAPI{
+		config:   config,
+		endpoint: endpoint,
+		pending:  map[storage.Path]pendingChange{},
+	}, nil
+}
+
+// pendingChange is a buffered write. A nil contents means the file was deleted.
+type pendingChange struct {
+	contents []byte
+}
+
+func (p pendingChange) deleted() bool {
+	return p.contents == nil
+}
+
+type storageAPI struct {
+	config   Config
+	endpoint *url.URL
+
+	lock    sync.Mutex
+	pending map[storage.Path]pendingChange
+}
+
+func (s *storageAPI) ListFiles(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	files, _, err := s.listObjects(ctx, s.dirPrefix(directory), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s (%w)", directory, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := map[string]struct{}{}
+	for _, file := range files {
+		result[file] = struct{}{}
+	}
+	for p, change := range s.pending {
+		if p.Basename() != directory {
+			continue
+		}
+		if change.deleted() {
+			delete(result, p.Filename())
+		} else {
+			result[p.Filename()] = struct{}{}
+		}
+	}
+	return mapkeys.Sorted(result), nil
+}
+
+func (s *storageAPI) ListDirectories(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	prefix := s.dirPrefix(directory)
+	_, directories, err := s.listObjects(ctx, prefix, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directories in %s (%w)", directory, err)
+	}
+
+	// Snapshot the pending changes in the directory, so the lock is not held during the network calls below.
+	s.lock.Lock()
+	pending := map[storage.Path]pendingChange{}
+	for p, change := range s.pending {
+		if _, ok := subdirectoryOf(directory, p); ok {
+			pending[p] = change
+		}
+	}
+	s.lock.Unlock()
+
+	result := map[string]struct{}{}
+	// deletedIn holds the remote directories with pending deletes. They may be empty after the commit.
+	deletedIn := map[string]struct{}{}
+	for p, change := range pending {
+		subdirectory, _ := subdirectoryOf(directory, p)
+		if change.deleted() {
+			deletedIn[subdirectory] = struct{}{}
+		} else {
+			result[subdirectory] = struct{}{}
+		}
+	}
+	for _, subdirectory := range directories {
+		if _, ok := result[subdirectory]; ok {
+			continue
+		}
+		if _, ok := deletedIn[subdirectory]; ok {
+			remaining, err := s.hasRemainingFiles(ctx, prefix+subdirectory+"/", pending)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list directories in %s (%w)", directory, err)
+			}
+			if !remaining {
+				continue
+			}
+		}
+		result[subdirectory] = struct{}{}
+	}
+	return mapkeys.Sorted(result), nil
+}
+
+// hasRemainingFiles checks if any object below the prefix is not deleted by one of the pending changes passed.
+func (s *storageAPI) hasRemainingFiles(ctx context.Context, prefix string, pending map[storage.Path]pendingChange) (bool, error) {
+	keys, _, err := s.listObjects(ctx, prefix, false)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		change, ok := pending[storage.Path(strings.TrimPrefix(prefix, s.config.Prefix)+key)]
+		if !ok || !change.deleted() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *storageAPI) PutFile(_ context.Context, path storage.Path, contents []byte) error {
+	if err := validateFilePath(path); err != nil {
+		return err
+	}
+	if contents == nil {
+		contents = []byte{}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending[path] = pendingChange{bytes.Clone(contents)}
+	return nil
+}
+
+func (s *storageAPI) GetFile(ctx context.Context, path storage.Path) ([]byte, error) {
+	if err := validateFilePath(path); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	change, ok := s.pending[path]
+	s.lock.Unlock()
+	if ok {
+		if change.deleted() {
+			return nil, &storage.ErrFileNotFound{Path: path}
+		}
+		return bytes.Clone(change.contents), nil
+	}
+
+	key := s.config.Prefix + string(path)
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &storage.ErrFileNotFound{Path: path}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newRequestFailedError(http.MethodGet, key, resp)
+	}
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &RequestFailedError{Method: http.MethodGet, Key: key, Cause: err}
+	}
+	return contents, nil
+}
+
+func (s *storageAPI) FileExists(ctx context.Context, path storage.Path) (bool, error) {
+	if err := validateFilePath(path); err != nil {
+		return false, err
+	}
+	s.lock.Lock()
+	change, ok := s.pending[path]
+	s.lock.Unlock()
+	if ok {
+		return !change.deleted(), nil
+	}
+
+	key := s.config.Prefix + string(path)
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, newRequestFailedError(http.MethodHead, key, resp)
+	}
+}
+
+func (s *storageAPI) DeleteFile(_ context.Context, path storage.Path) error {
+	if err := validateFilePath(path); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending[path] = pendingChange{nil}
+	return nil
+}
+
+func (s *storageAPI) Commit(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	paths := make([]storage.Path, 0, len(s.pending))
+	for p := range s.pending {
+		paths = append(paths, p)
+	}
+	// Upload the new files before deleting the old ones, so a failed commit does not leave the registry with
+	// missing files.
+	sort.Slice(paths, func(i, j int) bool {
+		deletedI := s.pending[paths[i]].deleted()
+		deletedJ := s.pending[paths[j]].deleted()
+		if deletedI != deletedJ {
+			return deletedJ
+		}
+		return paths[i] < paths[j]
+	})
+
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		change := s.pending[p]
+		key := s.config.Prefix + string(p)
+		if change.deleted() {
+			logger.LogTrace(ctx, s.config.Logger, "Deleting object %s...", key)
+			if err := s.deleteObject(ctx, key); err != nil {
+				return err
+			}
+		} else {
+			logger.LogTrace(ctx, s.config.Logger, "Uploading object %s...", key)
+			if err := s.putObject(ctx, key, change.contents); err != nil {
+				return err
+			}
+		}
+		delete(s.pending, p)
+	}
+	return nil
+}
+
+func (s *storageAPI) putObject(ctx context.Context, key string, contents []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, nil, contents)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return newRequestFailedError(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (s *storageAPI) deleteObject(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// S3 returns 204 for deleted objects, even if they did not exist. Some compatible services return 404 instead.
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return newRequestFailedError(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// listObjects lists the objects below the prefix using ListObjectsV2, following the continuation tokens. The
+// returned keys and common prefixes are relative to the prefix, and the common prefixes have no trailing slash. If
+// delimited is false, all objects below the prefix are returned and no common prefixes.
+func (s *storageAPI) listObjects(ctx context.Context, prefix string, delimited bool) ([]string, []string, error) {
+	var keys []string
+	var commonPrefixes []string
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if delimited {
+			query.Set("delimiter", "/")
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := newRequestFailedError(http.MethodGet, prefix, resp)
+			_ = resp.Body.Close()
+			return nil, nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, nil, &RequestFailedError{
+				Method: http.MethodGet,
+				Key:    prefix,
+				Cause:  fmt.Errorf("failed to parse object listing (%w)", err),
+			}
+		}
+		for _, object := range result.Contents {
+			// Skip the empty "directory" objects some tools create.
+			if key := strings.TrimPrefix(object.Key, prefix); key != "" && !strings.HasSuffix(key, "/") {
+				keys = append(keys, key)
+			}
+		}
+		for _, commonPrefix := range result.CommonPrefixes {
+			commonPrefixes = append(commonPrefixes, strings.TrimSuffix(strings.TrimPrefix(commonPrefix.Prefix, prefix), "/"))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, commonPrefixes, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// do sends a request for the object key, or for the bucket if the key is empty.
+func (s *storageAPI) do(ctx context.Context, method string, key string, query url.Values, body []byte) (*http.Response, error) {
+	reqURL := *s.endpoint
+	basePath := strings.TrimSuffix(reqURL.Path, "/")
+	if s.config.PathStyle {
+		basePath += "/" + EscapePath(s.config.Bucket)
+	} else {
+		reqURL.Host = s.config.Bucket + "." + reqURL.Host
+	}
+	reqURL.RawPath = basePath + "/" + EscapePath(key)
+	reqURL.Path, _ = url.PathUnescape(reqURL.RawPath)
+	reqURL.RawQuery = encodeQuery(query)
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), bodyReader)
+	if err != nil {
+		return nil, &RequestFailedError{Method: method, Key: key, Cause: err}
+	}
+	if s.config.Credentials != nil {
+		payloadHash := emptyPayloadHash
+		if body != nil {
+			payloadHash = hashHex(body)
+		}
+		SignV4(req, payloadHash, *s.config.Credentials, s.config.Region, s.config.TimeSource())
+	}
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &RequestFailedError{Method: method, Key: key, Cause: err}
+	}
+	return resp, nil
+}
+
+// dirPrefix returns the key prefix of the objects in the directory.
+func (s *storageAPI) dirPrefix(directory storage.Path) string {
+	if directory == "" {
+		return s.config.Prefix
+	}
+	return s.config.Prefix + string(directory) + "/"
+}
+
+type errorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func newRequestFailedError(method string, key string, resp *http.Response) error {
+	result := &RequestFailedError{
+		Method:     method,
+		Key:        key,
+		StatusCode: resp.StatusCode,
+	}
+	var errorBody errorResponse
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&errorBody); err == nil {
+		result.Code = errorBody.Code
+		result.Message = errorBody.Message
+	}
+	return result
+}
+
+// encodeQuery encodes the query parameters with the same escaping the signature uses.
+func encodeQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, escape(name, true)+"="+escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// subdirectoryOf returns the first path element of p below the directory if p is in a subdirectory of it.
+func subdirectoryOf(directory storage.Path, p storage.Path) (string, bool) {
+	rest := string(p)
+	if directory != "" {
+		if !strings.HasPrefix(rest, string(directory)+"/") {
+			return "", false
+		}
+		rest = strings.TrimPrefix(rest, string(directory)+"/")
+	}
+	subdirectory, _, ok := strings.Cut(rest, "/")
+	return subdirectory, ok
+}
+
+func validateFilePath(p storage.Path) error {
+	if p == "" {
+		return errors.New("invalid path: the file path must not be empty")
+	}
+	return p.Validate()
+}
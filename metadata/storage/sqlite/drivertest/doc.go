@@ -0,0 +1,6 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package drivertest runs the tests of the SQLite storage against the modernc.org/sqlite driver. It is a separate
+// module, so the library itself does not depend on a SQLite driver. Run the tests from this directory using go test.
+package drivertest
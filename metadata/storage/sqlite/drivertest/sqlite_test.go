@@ -0,0 +1,156 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package drivertest_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+	"github.com/opentofu/libregistry/metadata/storage/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+func TestFileHandling(t *testing.T) {
+	storage.TestStorageAPI(t, func(t *testing.T) storage.API {
+		return newTestStorage(t)
+	})
+}
+
+func TestFileAsDirectory(t *testing.T) {
+	ctx := context.Background()
+	api := newTestStorage(t)
+	if err := api.PutFile(ctx, "a/b", []byte("{}")); err != nil {
+		t.Fatalf("Failed to put file (%v)", err)
+	}
+
+	for _, p := range []storage.Path{"a/b/c", "a/b/c/d"} {
+		err := api.PutFile(ctx, p, []byte("{}"))
+		var alreadyExists *storage.ErrFileAlreadyExists
+		if !errors.As(err, &alreadyExists) {
+			t.Fatalf("Incorrect error when writing %s below a file (%v)", p, err)
+		}
+		if alreadyExists.Path != "a/b" {
+			t.Fatalf("Incorrect path in error: %s", alreadyExists.Path)
+		}
+	}
+	directories, err := api.ListDirectories(ctx, "a")
+	if err != nil {
+		t.Fatalf("Failed to list directories (%v)", err)
+	}
+	if len(directories) != 0 {
+		t.Fatalf("The failed write created directories: %v", directories)
+	}
+
+	// The reverse case: a file cannot replace a directory.
+	if err := api.PutFile(ctx, "a", []byte("{}")); err == nil {
+		t.Fatalf("Replacing a directory with a file did not fail.")
+	}
+}
+
+func TestQueries(t *testing.T) {
+	ctx := context.Background()
+	api := newTestStorage(t)
+	for _, p := range []storage.Path{
+		"modules/o/opentofu/vpc/aws.json",
+		"modules/o/opentofu/vpc/google.json",
+		"modules/o/opentofu-test/vpc/aws.json",
+		"modules/t/test/iam/aws.json",
+		"modules-other/x.json",
+		"providers/o/opentofu/aws.json",
+		"root.json",
+	} {
+		if err := api.PutFile(ctx, p, []byte(p)); err != nil {
+			t.Fatalf("Failed to put file (%v)", err)
+		}
+	}
+	if err := api.DeleteFile(ctx, "modules/t/test/iam/aws.json"); err != nil {
+		t.Fatalf("Failed to delete file (%v)", err)
+	}
+
+	for _, directory := range []storage.Path{"", "modules", "modules/o/opentofu", "modules/t", "missing"} {
+		t.Run(string(directory), func(t *testing.T) {
+			expected := walk(t, api, directory)
+
+			paths, err := api.ListFilesRecursive(ctx, directory)
+			if err != nil {
+				t.Fatalf("Failed to list files recursively (%v)", err)
+			}
+			sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+			expectedPaths := make([]storage.Path, 0, len(expected))
+			for p := range expected {
+				expectedPaths = append(expectedPaths, p)
+			}
+			sort.Slice(expectedPaths, func(i, j int) bool { return expectedPaths[i] < expectedPaths[j] })
+			if len(paths) != 0 || len(expectedPaths) != 0 {
+				if !reflect.DeepEqual(paths, expectedPaths) {
+					t.Fatalf("Incorrect recursive listing: %v (expected: %v)", paths, expectedPaths)
+				}
+			}
+
+			files, err := api.GetFilesRecursive(ctx, directory)
+			if err != nil {
+				t.Fatalf("Failed to get files recursively (%v)", err)
+			}
+			if !reflect.DeepEqual(files, expected) {
+				t.Fatalf("Incorrect recursive contents: %v (expected: %v)", files, expected)
+			}
+		})
+	}
+}
+
+// walk reads the files in the directory and its subdirectories using the basic storage functions.
+func walk(t *testing.T, api storage.API, directory storage.Path) map[storage.Path][]byte {
+	t.Helper()
+	ctx := context.Background()
+	join := func(name string) storage.Path {
+		if directory == "" {
+			return storage.Path(name)
+		}
+		return directory + "/" + storage.Path(name)
+	}
+	result := map[storage.Path][]byte{}
+	files, err := api.ListFiles(ctx, directory)
+	if err != nil {
+		t.Fatalf("Failed to list files in %s (%v)", directory, err)
+	}
+	for _, file := range files {
+		contents, err := api.GetFile(ctx, join(file))
+		if err != nil {
+			t.Fatalf("Failed to get file %s (%v)", join(file), err)
+		}
+		result[join(file)] = contents
+	}
+	directories, err := api.ListDirectories(ctx, directory)
+	if err != nil {
+		t.Fatalf("Failed to list directories in %s (%v)", directory, err)
+	}
+	for _, subdirectory := range directories {
+		for p, contents := range walk(t, api, join(subdirectory)) {
+			result[p] = contents
+		}
+	}
+	return result
+}
+
+func newTestStorage(t *testing.T) storage.QueryAPI {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "registry.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database (%v)", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	api, err := sqlite.New(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite storage (%v)", err)
+	}
+	return api
+}
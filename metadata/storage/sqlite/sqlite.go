@@ -0,0 +1,321 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package sqlite provides a storage implementation that keeps the files in a SQLite database. The directories are
+// indexed, so listing them and querying whole subtrees does not require walking the tree.
+//
+// The package works with any database/sql driver for SQLite, such as modernc.org/sqlite or
+// github.com/mattn/go-sqlite3. Import the driver of your choice and pass the opened database to New. The tests run
+// against modernc.org/sqlite in the separate drivertest module, so the library does not depend on a driver.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/libregistry/metadata/storage"
+)
+
+// schema creates the tables if they do not exist yet. Files are stored by their directory and name, so listing a
+// directory is an index lookup. The directories table holds every directory containing at least one file, directly
+// or in a subdirectory.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS files (
+		directory TEXT NOT NULL,
+		name TEXT NOT NULL,
+		contents BLOB NOT NULL,
+		PRIMARY KEY (directory, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS directories (
+		parent TEXT NOT NULL,
+		name TEXT NOT NULL,
+		PRIMARY KEY (parent, name)
+	)`,
+}
+
+// New creates a storage in the specified SQLite database and creates the tables if needed. The caller remains
+// responsible for closing the database. Writes are applied immediately, each in its own transaction.
+func New(ctx context.Context, db *sql.DB) (storage.QueryAPI, error) {
+	for _, statement := range schema {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, fmt.Errorf("failed to create the database schema (%w)", err)
+		}
+	}
+	return &storageAPI{
+		db: db,
+	}, nil
+}
+
+type storageAPI struct {
+	db *sql.DB
+}
+
+func (s *storageAPI) ListFiles(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	result, err := s.queryStrings(ctx, `SELECT name FROM files WHERE directory = ? ORDER BY name`, string(directory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s (%w)", directory, err)
+	}
+	return result, nil
+}
+
+func (s *storageAPI) ListDirectories(ctx context.Context, directory storage.Path) ([]string, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	result, err := s.queryStrings(ctx, `SELECT name FROM directories WHERE parent = ? ORDER BY name`, string(directory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directories in %s (%w)", directory, err)
+	}
+	return result, nil
+}
+
+func (s *storageAPI) PutFile(ctx context.Context, path storage.Path, contents []byte) error {
+	if err := validateFilePath(path); err != nil {
+		return err
+	}
+	if contents == nil {
+		// The column does not allow NULL values.
+		contents = []byte{}
+	}
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		var isDirectory bool
+		if err := tx.QueryRowContext(
+			ctx,
+			`SELECT EXISTS (SELECT 1 FROM directories WHERE parent = ? AND name = ?)`,
+			string(path.Basename()),
+			path.Filename(),
+		).Scan(&isDirectory); err != nil {
+			return fmt.Errorf("failed to check for directory %s (%w)", path, err)
+		}
+		if isDirectory {
+			return &storage.ErrFileAlreadyExists{Path: path}
+		}
+
+		for directory := path.Basename(); directory != ""; directory = directory.Basename() {
+			var isFile bool
+			if err := tx.QueryRowContext(
+				ctx,
+				`SELECT EXISTS (SELECT 1 FROM files WHERE directory = ? AND name = ?)`,
+				string(directory.Basename()),
+				directory.Filename(),
+			).Scan(&isFile); err != nil {
+				return fmt.Errorf("failed to check for file %s (%w)", directory, err)
+			}
+			if isFile {
+				return &storage.ErrFileAlreadyExists{Path: directory}
+			}
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT OR IGNORE INTO directories (parent, name) VALUES (?, ?)`,
+				string(directory.Basename()),
+				directory.Filename(),
+			); err != nil {
+				return fmt.Errorf("failed to create directory %s (%w)", directory, err)
+			}
+		}
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT OR REPLACE INTO files (directory, name, contents) VALUES (?, ?, ?)`,
+			string(path.Basename()),
+			path.Filename(),
+			contents,
+		); err != nil {
+			return fmt.Errorf("failed to write file %s (%w)", path, err)
+		}
+		return nil
+	})
+}
+
+func (s *storageAPI) GetFile(ctx context.Context, path storage.Path) ([]byte, error) {
+	if err := validateFilePath(path); err != nil {
+		return nil, err
+	}
+	var contents []byte
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT contents FROM files WHERE directory = ? AND name = ?`,
+		string(path.Basename()),
+		path.Filename(),
+	).Scan(&contents); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &storage.ErrFileNotFound{Path: path}
+		}
+		return nil, fmt.Errorf("failed to read file %s (%w)", path, err)
+	}
+	return contents, nil
+}
+
+func (s *storageAPI) FileExists(ctx context.Context, path storage.Path) (bool, error) {
+	if err := validateFilePath(path); err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM files WHERE directory = ? AND name = ?)`,
+		string(path.Basename()),
+		path.Filename(),
+	).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check file %s (%w)", path, err)
+	}
+	return exists, nil
+}
+
+func (s *storageAPI) DeleteFile(ctx context.Context, path storage.Path) error {
+	if err := validateFilePath(path); err != nil {
+		return err
+	}
+	return s.transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM files WHERE directory = ? AND name = ?`,
+			string(path.Basename()),
+			path.Filename(),
+		); err != nil {
+			return fmt.Errorf("failed to delete file %s (%w)", path, err)
+		}
+		// Remove the directories that became empty, starting with the innermost one.
+		for directory := path.Basename(); directory != ""; directory = directory.Basename() {
+			var empty bool
+			if err := tx.QueryRowContext(
+				ctx,
+				`SELECT NOT EXISTS (SELECT 1 FROM files WHERE directory = ?)
+					AND NOT EXISTS (SELECT 1 FROM directories WHERE parent = ?)`,
+				string(directory),
+				string(directory),
+			).Scan(&empty); err != nil {
+				return fmt.Errorf("failed to check directory %s (%w)", directory, err)
+			}
+			if !empty {
+				break
+			}
+			if _, err := tx.ExecContext(
+				ctx,
+				`DELETE FROM directories WHERE parent = ? AND name = ?`,
+				string(directory.Basename()),
+				directory.Filename(),
+			); err != nil {
+				return fmt.Errorf("failed to delete directory %s (%w)", directory, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *storageAPI) ListFilesRecursive(ctx context.Context, directory storage.Path) ([]storage.Path, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	query, args := subtreeQuery("directory, name", directory)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s (%w)", directory, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var result []storage.Path
+	for rows.Next() {
+		var fileDirectory, name string
+		if err := rows.Scan(&fileDirectory, &name); err != nil {
+			return nil, fmt.Errorf("failed to list files in %s (%w)", directory, err)
+		}
+		result = append(result, joinPath(fileDirectory, name))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list files in %s (%w)", directory, err)
+	}
+	return result, nil
+}
+
+func (s *storageAPI) GetFilesRecursive(ctx context.Context, directory storage.Path) (map[storage.Path][]byte, error) {
+	if err := directory.Validate(); err != nil {
+		return nil, err
+	}
+	query, args := subtreeQuery("directory, name, contents", directory)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read files in %s (%w)", directory, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	result := map[storage.Path][]byte{}
+	for rows.Next() {
+		var fileDirectory, name string
+		var contents []byte
+		if err := rows.Scan(&fileDirectory, &name, &contents); err != nil {
+			return nil, fmt.Errorf("failed to read files in %s (%w)", directory, err)
+		}
+		result[joinPath(fileDirectory, name)] = contents
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read files in %s (%w)", directory, err)
+	}
+	return result, nil
+}
+
+// subtreeQuery returns the query selecting the columns of all files in the directory and its subdirectories. The
+// subdirectories are selected with a range on the primary key: all paths starting with "directory/" sort between
+// "directory/" and "directory0", because "0" is the character after "/".
+func subtreeQuery(columns string, directory storage.Path) (string, []any) {
+	if directory == "" {
+		return `SELECT ` + columns + ` FROM files ORDER BY directory, name`, nil
+	}
+	return `SELECT ` + columns + ` FROM files WHERE directory = ? OR (directory >= ? AND directory < ?) ORDER BY directory, name`,
+		[]any{string(directory), string(directory) + "/", string(directory) + "0"}
+}
+
+func (s *storageAPI) queryStrings(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var result []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, rows.Err()
+}
+
+func (s *storageAPI) transaction(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction (%w)", err)
+	}
+	if err := f(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction (%w)", err)
+	}
+	return nil
+}
+
+func joinPath(directory string, name string) storage.Path {
+	if directory == "" {
+		return storage.Path(name)
+	}
+	return storage.Path(directory + "/" + name)
+}
+
+func validateFilePath(p storage.Path) error {
+	if strings.TrimSpace(string(p)) == "" {
+		return errors.New("invalid path: the file path must not be empty")
+	}
+	return p.Validate()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func TestYankModuleVersion(t *testing.T) {
+	api, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	ctx := context.Background()
+	moduleAddr := module.Addr{
+		Namespace:    "opentofu",
+		Name:         "test",
+		TargetSystem: "aws",
+	}
+	if err := api.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: module.VersionList{{Version: "v1.1.0"}, {Version: "v1.0.0"}},
+	}); err != nil {
+		t.Fatalf("Failed to put module (%v)", err)
+	}
+
+	if err := api.YankModuleVersion(ctx, moduleAddr, "v1.1.0", "Broken release"); err != nil {
+		t.Fatalf("Failed to yank module version (%v)", err)
+	}
+	if err := api.DeprecateModuleVersion(ctx, moduleAddr, "v1.0.0", "Use v2"); err != nil {
+		t.Fatalf("Failed to deprecate module version (%v)", err)
+	}
+	moduleMetadata, err := api.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatalf("Failed to get module (%v)", err)
+	}
+	if yanked := moduleMetadata.Versions[0].Yanked; yanked == nil || yanked.Reason != "Broken release" || yanked.Timestamp.IsZero() {
+		t.Fatalf("Incorrect yanked state: %v", yanked)
+	}
+	if moduleMetadata.Versions[0].Deprecated != nil || moduleMetadata.Versions[1].Yanked != nil {
+		t.Fatalf("Status set on the wrong version.")
+	}
+	if deprecated := moduleMetadata.Versions[1].Deprecated; deprecated == nil || deprecated.Reason != "Use v2" {
+		t.Fatalf("Incorrect deprecated state: %v", deprecated)
+	}
+
+	var alreadyYanked *metadata.ModuleVersionAlreadyYankedError
+	if err := api.YankModuleVersion(ctx, moduleAddr, "v1.1.0", "Yanked again"); !errors.As(err, &alreadyYanked) {
+		t.Fatalf("Incorrect error returned for yanking a yanked version: %v", err)
+	}
+	if err := api.UnyankModuleVersion(ctx, moduleAddr, "v1.1.0"); err != nil {
+		t.Fatalf("Failed to unyank module version (%v)", err)
+	}
+	if err := api.UndeprecateModuleVersion(ctx, moduleAddr, "v1.0.0"); err != nil {
+		t.Fatalf("Failed to undeprecate module version (%v)", err)
+	}
+	moduleMetadata, err = api.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatalf("Failed to get module (%v)", err)
+	}
+	if moduleMetadata.Versions[0].Yanked != nil || moduleMetadata.Versions[1].Deprecated != nil {
+		t.Fatalf("Status not removed.")
+	}
+
+	err = api.YankModuleVersion(ctx, moduleAddr, "v2.0.0", "")
+	var notFound *metadata.ModuleVersionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Incorrect error returned for a non-existent version: %v", err)
+	}
+}
+
+func TestYankProviderVersion(t *testing.T) {
+	api, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatalf("Failed to initialize API (%v)", err)
+	}
+	ctx := context.Background()
+	providerAddr := provider.Addr{
+		Namespace: "opentofu",
+		Name:      "test",
+	}
+	if err := api.PutProvider(ctx, providerAddr, provider.Metadata{
+		Versions: []provider.Version{{Version: "v1.0.0"}},
+	}); err != nil {
+		t.Fatalf("Failed to put provider (%v)", err)
+	}
+
+	if err := api.YankProviderVersion(ctx, providerAddr, "v1.0.0", "Compromised signing key"); err != nil {
+		t.Fatalf("Failed to yank provider version (%v)", err)
+	}
+	providerMetadata, err := api.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatalf("Failed to get provider (%v)", err)
+	}
+	if yanked := providerMetadata.Versions[0].Yanked; yanked == nil || yanked.Reason != "Compromised signing key" {
+		t.Fatalf("Incorrect yanked state: %v", yanked)
+	}
+
+	var alreadyYanked *metadata.ProviderVersionAlreadyYankedError
+	if err := api.YankProviderVersion(ctx, providerAddr, "v1.0.0", "Yanked again"); !errors.As(err, &alreadyYanked) {
+		t.Fatalf("Incorrect error returned for yanking a yanked version: %v", err)
+	}
+	if err := api.UnyankProviderVersion(ctx, providerAddr, "v1.0.0"); err != nil {
+		t.Fatalf("Failed to unyank provider version (%v)", err)
+	}
+	providerMetadata, err = api.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		t.Fatalf("Failed to get provider (%v)", err)
+	}
+	if providerMetadata.Versions[0].Yanked != nil {
+		t.Fatalf("Yanked status not removed.")
+	}
+
+	err = api.DeprecateProviderVersion(ctx, providerAddr, "v2.0.0", "")
+	var notFound *metadata.ProviderVersionNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Incorrect error returned for a non-existent version: %v", err)
+	}
+}
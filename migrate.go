@@ -0,0 +1,199 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+)
+
+func (m api) MigrateModule(ctx context.Context, moduleAddr module.Addr) (module.Addr, error) {
+	if err := moduleAddr.Validate(); err != nil {
+		return moduleAddr, &ModuleMigrationFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		return moduleAddr, &ModuleMigrationFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	repo, err := m.getModuleRepo(moduleAddr, moduleMetadata)
+	if err != nil {
+		return moduleAddr, &ModuleMigrationFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	info, err := m.vcsClient.GetRepositoryInfo(ctx, repo.addr)
+	if err != nil {
+		return moduleAddr, &ModuleMigrationFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if info.MovedTo == nil {
+		return moduleAddr, nil
+	}
+
+	newAddr, err := module.AddrFromRepository(*info.MovedTo)
+	if moduleMetadata.CustomRepository != "" || info.MovedTo.Host != "" || err != nil {
+		// The module address does not depend on the repository name, so only the repository reference changes.
+		moduleMetadata.CustomRepository = info.MovedTo.String()
+		if err := m.dataAPI.PutModule(ctx, moduleAddr, moduleMetadata); err != nil {
+			return moduleAddr, &ModuleMigrationFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+		return moduleAddr, nil
+	}
+	if newAddr.Equals(moduleAddr) {
+		return moduleAddr, nil
+	}
+	if err := m.moveModule(ctx, moduleAddr, newAddr, moduleMetadata); err != nil {
+		return moduleAddr, &ModuleMigrationFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	return newAddr.Normalize(), nil
+}
+
+// moveModule moves the module metadata, the analysis results of all versions and, if a docs harvester is configured,
+// the documentation to the new address and leaves an alias at the old address.
+func (m api) moveModule(ctx context.Context, from module.Addr, to module.Addr, moduleMetadata module.Metadata) error {
+	if err := m.checkModuleBlocklist(ctx, to); err != nil {
+		return err
+	}
+	_, err := m.dataAPI.GetModule(ctx, to, false)
+	if err == nil {
+		return &ModuleAlreadyExistsError{to}
+	}
+	var notFoundErr *metadata.ModuleNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return err
+	}
+	if err := m.dataAPI.PutModule(ctx, to, moduleMetadata); err != nil {
+		return err
+	}
+	for _, ver := range moduleMetadata.Versions {
+		details, err := m.dataAPI.GetModuleVersionDetails(ctx, from, ver.Version)
+		if err != nil {
+			var detailsNotFoundErr *metadata.ModuleVersionDetailsNotFoundError
+			if errors.As(err, &detailsNotFoundErr) {
+				continue
+			}
+			return err
+		}
+		if err := m.dataAPI.PutModuleVersionDetails(ctx, to, ver.Version, details); err != nil {
+			return err
+		}
+		if err := m.dataAPI.DeleteModuleVersionDetails(ctx, from, ver.Version); err != nil {
+			return err
+		}
+	}
+	if err := m.dataAPI.DeleteModule(ctx, from); err != nil {
+		return err
+	}
+	if err := m.dataAPI.PutModuleAlias(ctx, from, to); err != nil {
+		return err
+	}
+	if m.config.DocsHarvester != nil && m.config.DryRun == nil {
+		if err := m.config.DocsHarvester.MoveModuleDocs(ctx, from, to); err != nil {
+			return fmt.Errorf("failed to move the documentation (%w)", err)
+		}
+	}
+	return nil
+}
+
+func (m api) MigrateProvider(ctx context.Context, providerAddr provider.Addr) (provider.Addr, error) {
+	if m.providerDataAPI == nil {
+		if m.config.DryRun != nil {
+			return providerAddr, &DryRunNotSupportedError{"MigrateProvider"}
+		}
+		return providerAddr, &ProviderMigrationFailedError{
+			providerAddr,
+			fmt.Errorf("the data API passed to the registry API does not support providers"),
+		}
+	}
+	providerAddr = providerAddr.Normalize()
+	providerMetadata, err := m.providerDataAPI.GetProvider(ctx, providerAddr, false)
+	if err != nil {
+		return providerAddr, &ProviderMigrationFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	repository := providerAddr.ToRepositoryAddr()
+	if providerMetadata.CustomRepository != "" {
+		repository, err = m.vcsClient.ParseRepositoryAddr(providerMetadata.CustomRepository)
+		if err != nil {
+			return providerAddr, &ProviderMigrationFailedError{
+				providerAddr,
+				fmt.Errorf("failed to parse custom repository %s (%w)", providerMetadata.CustomRepository, err),
+			}
+		}
+	}
+	info, err := m.vcsClient.GetRepositoryInfo(ctx, repository)
+	if err != nil {
+		return providerAddr, &ProviderMigrationFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	if info.MovedTo == nil {
+		return providerAddr, nil
+	}
+
+	newAddr, err := provider.AddrFromRepository(*info.MovedTo)
+	if providerMetadata.CustomRepository != "" || info.MovedTo.Host != "" || err != nil {
+		providerMetadata.CustomRepository = info.MovedTo.String()
+		if err := m.providerDataAPI.PutProvider(ctx, providerAddr, providerMetadata); err != nil {
+			return providerAddr, &ProviderMigrationFailedError{
+				providerAddr,
+				err,
+			}
+		}
+		return providerAddr, nil
+	}
+	newAddr = newAddr.Normalize()
+	if newAddr.Equals(providerAddr) {
+		return providerAddr, nil
+	}
+	if err := m.moveProvider(ctx, providerAddr, newAddr, providerMetadata); err != nil {
+		return providerAddr, &ProviderMigrationFailedError{
+			providerAddr,
+			err,
+		}
+	}
+	return newAddr, nil
+}
+
+// moveProvider moves the provider metadata to the new address and leaves an alias at the old address.
+func (m api) moveProvider(ctx context.Context, from provider.Addr, to provider.Addr, providerMetadata provider.Metadata) error {
+	_, err := m.providerDataAPI.GetProvider(ctx, to, false)
+	if err == nil {
+		return &ProviderAlreadyExistsError{to}
+	}
+	var notFoundErr *metadata.ProviderNotFoundError
+	if !errors.As(err, &notFoundErr) {
+		return err
+	}
+	if err := m.providerDataAPI.PutProvider(ctx, to, providerMetadata); err != nil {
+		return err
+	}
+	if err := m.providerDataAPI.DeleteProvider(ctx, from); err != nil {
+		return err
+	}
+	return m.providerDataAPI.PutProviderAlias(ctx, from, to)
+}
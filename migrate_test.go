@@ -0,0 +1,247 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/docs"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/types/provider"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestMigrateModule(t *testing.T) {
+	oldAddr := module.Addr{Namespace: "old-org", Name: "iam", TargetSystem: "aws"}
+	newAddr := module.Addr{Namespace: "new-org", Name: "identity", TargetSystem: "aws"}
+	oldRepo := oldAddr.ToRepositoryAddr()
+	newRepo := newAddr.ToRepositoryAddr()
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	docsStorage := memory.New()
+	harvester, err := docs.New(inMemoryVCS, docsStorage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithDocsHarvester(harvester))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, org := range []vcs.OrganizationAddr{oldRepo.Org, newRepo.Org} {
+		if err := inMemoryVCS.CreateOrganization(org); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inMemoryVCS.CreateRepository(oldRepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(oldRepo, "v1.0.0", fstest.MapFS{
+		"README.md": {Data: []byte("# IAM module")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddModule(ctx, oldRepo.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModuleVersionDetails(ctx, oldAddr, "v1.0.0", module.VersionDetails{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := harvester.HarvestModuleVersion(ctx, oldAddr, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A module whose repository was not moved stays in place.
+	currentAddr, err := registry.MigrateModule(ctx, oldAddr)
+	if err != nil {
+		t.Fatalf("Failed to migrate module (%v)", err)
+	}
+	if !currentAddr.Equals(oldAddr) {
+		t.Fatalf("A module was moved to %s without a repository move.", currentAddr)
+	}
+
+	if err := inMemoryVCS.MoveRepository(oldRepo, newRepo); err != nil {
+		t.Fatal(err)
+	}
+	var repoNotFound *vcs.RepositoryNotFoundError
+	if _, err := registry.UpdateModule(ctx, oldAddr); !errors.As(err, &repoNotFound) {
+		t.Fatalf("Updating a module with a moved repository did not fail (%v)", err)
+	}
+
+	currentAddr, err = registry.MigrateModule(ctx, oldAddr)
+	if err != nil {
+		t.Fatalf("Failed to migrate module (%v)", err)
+	}
+	if !currentAddr.Equals(newAddr) {
+		t.Fatalf("Incorrect new module address: %s", currentAddr)
+	}
+	var notFound *metadata.ModuleNotFoundError
+	if _, err := dataAPI.GetModule(ctx, oldAddr, false); !errors.As(err, &notFound) {
+		t.Fatalf("The module was not removed from the old address (%v)", err)
+	}
+	if _, err := dataAPI.GetModuleVersionDetails(ctx, newAddr, "v1.0.0"); err != nil {
+		t.Fatalf("The version details were not moved (%v)", err)
+	}
+	if _, err := harvester.GetModuleDocs(ctx, newAddr, "v1.0.0"); err != nil {
+		t.Fatalf("The documentation was not moved (%v)", err)
+	}
+	if _, err := dataAPI.GetModule(ctx, oldAddr, true); err != nil {
+		t.Fatalf("The module cannot be found through its old address (%v)", err)
+	}
+	aliases, err := dataAPI.ListModuleAliases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target, ok := aliases[oldAddr]; !ok || !target.Equals(newAddr) {
+		t.Fatalf("No alias was left at the old address: %v", aliases)
+	}
+	if _, err := registry.UpdateModule(ctx, newAddr); err != nil {
+		t.Fatalf("Failed to update the migrated module (%v)", err)
+	}
+}
+
+func TestMigrateModuleCustomRepository(t *testing.T) {
+	moduleAddr := module.Addr{Namespace: "test", Name: "iam", TargetSystem: "aws"}
+	oldRepo := vcs.RepositoryAddr{Org: "test", Name: "iam-module"}
+	newRepo := vcs.RepositoryAddr{Org: "test", Name: "identity-module"}
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(oldRepo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(oldRepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{CustomRepository: oldRepo.String()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.MoveRepository(oldRepo, newRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	currentAddr, err := registry.MigrateModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatalf("Failed to migrate module (%v)", err)
+	}
+	if !currentAddr.Equals(moduleAddr) {
+		t.Fatalf("A module with a custom repository was moved to %s.", currentAddr)
+	}
+	moduleMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moduleMetadata.CustomRepository != newRepo.String() {
+		t.Fatalf("Incorrect custom repository: %s", moduleMetadata.CustomRepository)
+	}
+}
+
+// TestMigrateModuleCustomRepositoryOtherHost tests that the host prefix of a custom repository on a different VCS
+// host is kept when the repository is renamed.
+func TestMigrateModuleCustomRepositoryOtherHost(t *testing.T) {
+	moduleAddr := module.Addr{Namespace: "test", Name: "iam", TargetSystem: "aws"}
+	oldRepo := vcs.RepositoryAddr{Org: "test", Name: "iam-module"}
+	newRepo := vcs.RepositoryAddr{Org: "test", Name: "identity-module"}
+
+	otherVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(fakevcs.New(), dataAPI, libregistry.WithVCSClient("git.example.com", otherVCS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateOrganization(oldRepo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateRepository(oldRepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{CustomRepository: "git.example.com/" + oldRepo.String()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.MoveRepository(oldRepo, newRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.MigrateModule(ctx, moduleAddr); err != nil {
+		t.Fatalf("Failed to migrate module (%v)", err)
+	}
+	moduleMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moduleMetadata.CustomRepository != "git.example.com/"+newRepo.String() {
+		t.Fatalf("Incorrect custom repository: %s", moduleMetadata.CustomRepository)
+	}
+}
+
+func TestMigrateProvider(t *testing.T) {
+	oldAddr := provider.Addr{Namespace: "old-org", Name: "test"}
+	newAddr := provider.Addr{Namespace: "new-org", Name: "test"}
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, org := range []vcs.OrganizationAddr{"old-org", "new-org"} {
+		if err := inMemoryVCS.CreateOrganization(org); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inMemoryVCS.CreateRepository(oldAddr.ToRepositoryAddr(), vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutProvider(ctx, oldAddr, provider.Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.MoveRepository(oldAddr.ToRepositoryAddr(), newAddr.ToRepositoryAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	currentAddr, err := registry.MigrateProvider(ctx, oldAddr)
+	if err != nil {
+		t.Fatalf("Failed to migrate provider (%v)", err)
+	}
+	if !currentAddr.Equals(newAddr) {
+		t.Fatalf("Incorrect new provider address: %s", currentAddr)
+	}
+	canonicalAddr, err := dataAPI.GetProviderCanonicalAddr(ctx, oldAddr)
+	if err != nil {
+		t.Fatalf("Failed to resolve the old provider address (%v)", err)
+	}
+	if !canonicalAddr.Equals(newAddr) {
+		t.Fatalf("The old address resolves to %s instead of %s.", canonicalAddr, newAddr)
+	}
+	if _, err := dataAPI.GetProvider(ctx, oldAddr, true); err != nil {
+		t.Fatalf("Failed to get the provider by its old address (%v)", err)
+	}
+}
@@ -5,15 +5,30 @@ package libregistry
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/opentofu/libregistry/license"
 	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
 )
 
-func (m api) AddModule(ctx context.Context, repository string) error {
+func (m api) AddModule(ctx context.Context, repository string, opts ...AddModuleOpt) error {
+	options := AddModuleOptions{}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return err
+		}
+	}
+
 	githubRepository, err := m.vcsClient.ParseRepositoryAddr(repository)
 	if err != nil {
 		return err
 	}
+	if githubRepository.Host != "" {
+		// The module address has no host, so modules on other hosts are only reachable through a custom repository.
+		return fmt.Errorf("cannot add %s: repositories on other VCS hosts must be set as the custom repository of the module", repository)
+	}
 
 	submitted, err := module.AddrFromRepository(githubRepository)
 	if err != nil {
@@ -27,6 +42,21 @@ func (m api) AddModule(ctx context.Context, repository string) error {
 		}
 	}
 
+	if err := m.checkModuleBlocklist(ctx, submitted); err != nil {
+		var blockedErr *ModuleBlockedError
+		if errors.As(err, &blockedErr) {
+			return err
+		}
+		return &ModuleAddFailedError{
+			submitted,
+			err,
+		}
+	}
+
+	if err := m.checkSubmitterPermission(ctx, submitted, options.Submitter, githubRepository.Org); err != nil {
+		return err
+	}
+
 	modules, err := m.dataAPI.ListModules(ctx)
 	if err != nil {
 		return err
@@ -38,5 +68,93 @@ func (m api) AddModule(ctx context.Context, repository string) error {
 		}
 	}
 
-	return m.UpdateModule(ctx, submitted)
+	if m.config.AdmissionPolicy != nil {
+		violations, err := m.checkAdmission(ctx, submitted)
+		if err != nil {
+			return &ModuleAddFailedError{
+				submitted,
+				err,
+			}
+		}
+		if len(violations) > 0 {
+			return &ModuleRejectedError{
+				submitted,
+				violations,
+			}
+		}
+	}
+
+	var warnings []string
+	if m.config.LicensePolicy != nil {
+		action, err := m.checkLicensePolicy(ctx, submitted)
+		switch {
+		case action == license.ActionReject:
+			return &ModuleAddFailedError{
+				submitted,
+				err,
+			}
+		case action == license.ActionFlag:
+			warnings = append(warnings, err.Error())
+		case err != nil:
+			return &ModuleAddFailedError{
+				submitted,
+				err,
+			}
+		}
+	}
+
+	if _, err := m.UpdateModule(ctx, submitted); err != nil {
+		return err
+	}
+	if len(warnings) == 0 {
+		return nil
+	}
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, submitted, false)
+	if err != nil {
+		return &ModuleAddFailedError{
+			submitted,
+			err,
+		}
+	}
+	moduleMetadata.Warnings = append(moduleMetadata.Warnings, warnings...)
+	if err := m.dataAPI.PutModule(ctx, submitted, moduleMetadata); err != nil {
+		return &ModuleAddFailedError{
+			submitted,
+			err,
+		}
+	}
+	return nil
+}
+
+// checkLicensePolicy evaluates the license policy against the latest version of the module. Modules without any
+// versions are allowed. If the licenses cannot be detected, the action is empty and an error is returned.
+func (m api) checkLicensePolicy(ctx context.Context, moduleAddr module.Addr) (license.Action, error) {
+	repo, err := m.getModuleRepo(moduleAddr, module.Metadata{})
+	if err != nil {
+		return "", err
+	}
+	tags, err := m.vcsClient.ListLatestTags(ctx, repo.addr)
+	if err != nil {
+		return "", err
+	}
+	var latest *vcs.Version
+	var latestVersion module.VersionNumber
+	for i, tag := range tags {
+		ver, err := module.VersionFromVCS(tag.VersionNumber)
+		if err != nil {
+			continue
+		}
+		if latest == nil || ver.Compare(latestVersion) > 0 {
+			latest = &tags[i]
+			latestVersion = ver
+		}
+	}
+	if latest == nil {
+		return license.ActionAllow, nil
+	}
+	licenses, err := m.detectLicenses(ctx, repo, latest.VersionNumber)
+	if err != nil {
+		return "", err
+	}
+	return m.config.LicensePolicy.Evaluate(licenses)
 }
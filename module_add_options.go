@@ -0,0 +1,29 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// AddModuleOpt is a function that modifies the options of a single AddModule call.
+type AddModuleOpt func(options *AddModuleOptions) error
+
+// AddModuleOptions holds the options of a single AddModule call.
+type AddModuleOptions struct {
+	// Submitter is the user requesting the module. If set, the user must have permission to act on behalf of the
+	// organization owning the repository, unless they are an admin or an allowed bot.
+	Submitter vcs.Username
+}
+
+// WithSubmitter sets the user who requested the module, enabling the ownership check.
+func WithSubmitter(submitter vcs.Username) AddModuleOpt {
+	return func(options *AddModuleOptions) error {
+		if err := submitter.Validate(); err != nil {
+			return err
+		}
+		options.Submitter = submitter
+		return nil
+	}
+}
@@ -107,7 +107,7 @@ func TestAddModule(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr)
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,63 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/moduleanalysis"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (m api) AnalyzeModuleVersion(ctx context.Context, moduleAddr module.Addr, version module.VersionNumber) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+	if err := version.Validate(); err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		var notFoundError *metadata.ModuleNotFoundError
+		if !errors.As(err, &notFoundError) {
+			return &ModuleAnalysisFailedError{moduleAddr, version, err}
+		}
+		moduleMetadata = module.Metadata{}
+	}
+	repo, err := m.getModuleRepo(moduleAddr, moduleMetadata)
+	if err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+	tag := version.ToVCSVersion()
+	for _, ver := range moduleMetadata.Versions {
+		if ver.Version.Normalize() == version.Normalize() {
+			tag = ver.ToVCSVersion()
+			break
+		}
+	}
+
+	workingCopy, err := m.vcsClient.Checkout(ctx, repo.addr, tag)
+	if err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+	var details module.VersionDetails
+	moduleFS, err := repo.moduleFS(workingCopy)
+	if err == nil {
+		details, err = moduleanalysis.AnalyzeFS(moduleFS)
+	}
+	if closeErr := workingCopy.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+
+	if err := m.dataAPI.PutModuleVersionDetails(ctx, moduleAddr, version, details); err != nil {
+		return &ModuleAnalysisFailedError{moduleAddr, version, err}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestAnalyzeModuleVersion(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	org := vcs.OrganizationAddr(moduleAddr.Namespace)
+	repo := vcs.RepositoryAddr{
+		Org:  org,
+		Name: "terraform-" + moduleAddr.TargetSystem + "-" + moduleAddr.Name,
+	}
+
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", fstest.MapFS{
+		"main.tf":              {Data: []byte(`variable "role_name" {}`)},
+		"modules/user/main.tf": {Data: []byte(`output "user_arn" { value = "" }`)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.AnalyzeModuleVersion(ctx, moduleAddr, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := dataAPI.GetModuleVersionDetails(ctx, moduleAddr, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details.Root.Variables) != 1 || details.Root.Variables[0].Name != "role_name" {
+		t.Fatalf("Incorrect root variables: %v", details.Root.Variables)
+	}
+	if len(details.Submodules) != 1 || len(details.Submodules[0].Outputs) != 1 {
+		t.Fatalf("Incorrect submodules: %v", details.Submodules)
+	}
+
+	if _, err := dataAPI.GetModuleVersionDetails(ctx, moduleAddr, "v2.0.0"); err == nil {
+		t.Fatalf("Reading details for a non-existent version did not fail.")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/license"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestAddModuleLicensePolicy(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+	licensed := vcs.RepositoryAddr{Org: "test", Name: "terraform-aws-licensed"}
+	unlicensed := vcs.RepositoryAddr{Org: "test", Name: "terraform-aws-unlicensed"}
+	for repo, contents := range map[vcs.RepositoryAddr]fstest.MapFS{
+		licensed:   {"LICENSE": {Data: []byte("Mozilla Public License Version 2.0")}},
+		unlicensed: {"main.tf": {Data: []byte("")}},
+	} {
+		if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		dataAPI, err := metadata.New(memory.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithLicensePolicy(license.Policy{
+			Allowed: []string{"MPL-2.0"},
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = registry.AddModule(ctx, unlicensed.String())
+		var violation *license.PolicyViolationError
+		if !errors.As(err, &violation) {
+			t.Fatalf("Adding an unlicensed module did not return a policy violation (%v)", err)
+		}
+		if _, err := dataAPI.GetModule(ctx, module.Addr{Namespace: "test", Name: "unlicensed", TargetSystem: "aws"}, false); err == nil {
+			t.Fatalf("The rejected module was stored.")
+		}
+
+		if err := registry.AddModule(ctx, licensed.String()); err != nil {
+			t.Fatalf("Failed to add licensed module (%v)", err)
+		}
+		moduleMetadata, err := dataAPI.GetModule(ctx, module.Addr{Namespace: "test", Name: "licensed", TargetSystem: "aws"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if licenses := moduleMetadata.Versions[0].Licenses; len(licenses) != 1 || licenses[0] != "MPL-2.0" {
+			t.Fatalf("Incorrect licenses recorded: %v", licenses)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		dataAPI, err := metadata.New(memory.New())
+		if err != nil {
+			t.Fatal(err)
+		}
+		registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithLicensePolicy(license.Policy{
+			Unlicensed: license.ActionFlag,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := registry.AddModule(ctx, unlicensed.String()); err != nil {
+			t.Fatalf("Failed to add flagged module (%v)", err)
+		}
+		moduleMetadata, err := dataAPI.GetModule(ctx, module.Addr{Namespace: "test", Name: "unlicensed", TargetSystem: "aws"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(moduleMetadata.Warnings) != 1 {
+			t.Fatalf("Incorrect warnings recorded: %v", moduleMetadata.Warnings)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"strings"
+
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// checkSubmitterPermission verifies that the submitter may add a module from a repository of the given organization.
+// Admins and allowed bots may add modules to any namespace. If no submitter is given, the check is skipped unless
+// the registry requires a submitter.
+func (m api) checkSubmitterPermission(ctx context.Context, moduleAddr module.Addr, submitter vcs.Username, organization vcs.OrganizationAddr) error {
+	if submitter == "" {
+		if m.config.RequireSubmitter {
+			return &SubmitterRequiredError{moduleAddr}
+		}
+		return nil
+	}
+	if containsUsername(m.config.Admins, submitter) {
+		m.config.Logger.Info(ctx, "Admin %s added the module %s, skipping the ownership check.", submitter, moduleAddr)
+		return nil
+	}
+	if containsUsername(m.config.AllowedBots, submitter) {
+		m.config.Logger.Debug(ctx, "Allowed bot %s added the module %s, skipping the ownership check.", submitter, moduleAddr)
+		return nil
+	}
+	permitted, err := m.vcsClient.HasPermission(ctx, submitter, organization)
+	if err != nil {
+		return &PermissionCheckFailedError{
+			moduleAddr,
+			submitter,
+			organization,
+			err,
+		}
+	}
+	if !permitted {
+		return &SubmitterNotPermittedError{
+			moduleAddr,
+			submitter,
+			organization,
+		}
+	}
+	return nil
+}
+
+// containsUsername compares the usernames case-insensitively, like GitHub does.
+func containsUsername(usernames []vcs.Username, username vcs.Username) bool {
+	for _, u := range usernames {
+		if strings.EqualFold(string(u), string(username)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestAddModuleSubmitterPermission(t *testing.T) {
+	const member = vcs.Username("member")
+	const outsider = vcs.Username("outsider")
+	const admin = vcs.Username("admin")
+	const bot = vcs.Username("registry-bot")
+
+	for _, tc := range []struct {
+		name          string
+		opts          []libregistry.Opt
+		submitter     vcs.Username
+		expectedError error
+	}{
+		{name: "no-submitter"},
+		{name: "member", submitter: member},
+		{name: "outsider", submitter: outsider, expectedError: &libregistry.SubmitterNotPermittedError{}},
+		{name: "admin", opts: []libregistry.Opt{libregistry.WithAdmins(admin)}, submitter: "Admin"},
+		{name: "bot", opts: []libregistry.Opt{libregistry.WithAllowedBots(bot)}, submitter: bot},
+		{name: "required", opts: []libregistry.Opt{libregistry.WithRequireSubmitter(true)}, expectedError: &libregistry.SubmitterRequiredError{}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inMemoryVCS := fakevcs.New()
+			ctx := context.Background()
+			dataAPI, err := metadata.New(memory.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			registry, err := libregistry.New(inMemoryVCS, dataAPI, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo := vcs.RepositoryAddr{Org: "test", Name: "terraform-aws-iam"}
+			if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+				t.Fatal(err)
+			}
+			for _, user := range []vcs.Username{member, outsider, admin, bot} {
+				if err := inMemoryVCS.AddUser(user); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := inMemoryVCS.AddMember(repo.Org, member); err != nil {
+				t.Fatal(err)
+			}
+
+			var opts []libregistry.AddModuleOpt
+			if tc.submitter != "" {
+				opts = append(opts, libregistry.WithSubmitter(tc.submitter))
+			}
+			err = registry.AddModule(ctx, repo.String(), opts...)
+			switch e := tc.expectedError.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Failed to add module (%v)", err)
+				}
+			case *libregistry.SubmitterNotPermittedError:
+				if !errors.As(err, &e) {
+					t.Fatalf("Expected a SubmitterNotPermittedError, got %v", err)
+				}
+			case *libregistry.SubmitterRequiredError:
+				if !errors.As(err, &e) {
+					t.Fatalf("Expected a SubmitterRequiredError, got %v", err)
+				}
+			}
+			if tc.expectedError != nil {
+				modules, err := dataAPI.ListModules(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(modules) != 0 {
+					t.Fatalf("The module was added despite the failed permission check.")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (m api) RemoveModule(ctx context.Context, moduleAddr module.Addr, reason string) error {
+	if err := moduleAddr.Validate(); err != nil {
+		return &ModuleRemoveFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	// Block the module first so a concurrent update cannot recreate it after the deletion.
+	blocklist, err := m.dataAPI.GetModuleBlocklist(ctx)
+	if err != nil {
+		return &ModuleRemoveFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	blocklist.BlockModule(moduleAddr, module.BlocklistEntry{
+		Reason:    reason,
+		Timestamp: time.Now().UTC(),
+	})
+	if err := m.dataAPI.PutModuleBlocklist(ctx, blocklist); err != nil {
+		return &ModuleRemoveFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	// The documentation may exist without the metadata, e.g. if an earlier removal failed halfway.
+	if m.config.DocsHarvester != nil && m.config.DryRun == nil {
+		if err := m.config.DocsHarvester.DeleteModuleDocs(ctx, moduleAddr); err != nil {
+			return &ModuleRemoveFailedError{
+				moduleAddr,
+				fmt.Errorf("failed to delete the documentation (%w)", err),
+			}
+		}
+	}
+
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		var notFoundError *metadata.ModuleNotFoundError
+		if errors.As(err, &notFoundError) {
+			return nil
+		}
+		return &ModuleRemoveFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	for _, ver := range moduleMetadata.Versions {
+		if err := m.dataAPI.DeleteModuleVersionDetails(ctx, moduleAddr, ver.Version); err != nil {
+			return &ModuleRemoveFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+	}
+	if err := m.dataAPI.DeleteModule(ctx, moduleAddr); err != nil {
+		return &ModuleRemoveFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	return nil
+}
+
+// checkModuleBlocklist returns a *ModuleBlockedError if the module or its namespace is on the blocklist.
+func (m api) checkModuleBlocklist(ctx context.Context, moduleAddr module.Addr) error {
+	blocklist, err := m.dataAPI.GetModuleBlocklist(ctx)
+	if err != nil {
+		return err
+	}
+	if entry, blocked := blocklist.IsBlocked(moduleAddr); blocked {
+		return &ModuleBlockedError{
+			moduleAddr,
+			entry.Reason,
+		}
+	}
+	return nil
+}
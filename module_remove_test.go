@@ -0,0 +1,132 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/docs"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestRemoveModule(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	harvester, err := docs.New(inMemoryVCS, memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI, libregistry.WithDocsHarvester(harvester))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", fstest.MapFS{
+		"README.md": {Data: []byte("# IAM module")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModuleVersionDetails(ctx, moduleAddr, "v1.0.0", module.VersionDetails{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := harvester.HarvestModuleVersion(ctx, moduleAddr, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.RemoveModule(ctx, moduleAddr, "removed on request of the author"); err != nil {
+		t.Fatalf("Failed to remove module (%v)", err)
+	}
+	var notFound *metadata.ModuleNotFoundError
+	if _, err := dataAPI.GetModule(ctx, moduleAddr, false); !errors.As(err, &notFound) {
+		t.Fatalf("The module was not deleted (%v)", err)
+	}
+	var detailsNotFound *metadata.ModuleVersionDetailsNotFoundError
+	if _, err := dataAPI.GetModuleVersionDetails(ctx, moduleAddr, "v1.0.0"); !errors.As(err, &detailsNotFound) {
+		t.Fatalf("The module version details were not deleted (%v)", err)
+	}
+	var docsNotFound *docs.DocsNotFoundError
+	if _, err := harvester.GetModuleDocs(ctx, moduleAddr, "v1.0.0"); !errors.As(err, &docsNotFound) {
+		t.Fatalf("The module documentation was not deleted (%v)", err)
+	}
+
+	var blockedErr *libregistry.ModuleBlockedError
+	err = registry.AddModule(ctx, repo.String())
+	if !errors.As(err, &blockedErr) {
+		t.Fatalf("Adding a removed module did not return a blocked error (%v)", err)
+	}
+	if blockedErr.Reason != "removed on request of the author" {
+		t.Fatalf("Incorrect block reason: %s", blockedErr.Reason)
+	}
+	if _, err := registry.UpdateModule(ctx, moduleAddr); !errors.As(err, &blockedErr) {
+		t.Fatalf("Updating a removed module did not return a blocked error (%v)", err)
+	}
+	if _, err := dataAPI.GetModule(ctx, moduleAddr, false); !errors.As(err, &notFound) {
+		t.Fatalf("The removed module was recreated (%v)", err)
+	}
+}
+
+func TestAddModuleBlockedNamespace(t *testing.T) {
+	repo := vcs.RepositoryAddr{Org: "spam", Name: "terraform-aws-iam"}
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	blocklist := module.Blocklist{}
+	blocklist.BlockNamespace("Spam", module.BlocklistEntry{Reason: "spam"})
+	if err := dataAPI.PutModuleBlocklist(ctx, blocklist); err != nil {
+		t.Fatal(err)
+	}
+
+	var blockedErr *libregistry.ModuleBlockedError
+	if err := registry.AddModule(ctx, repo.String()); !errors.As(err, &blockedErr) {
+		t.Fatalf("Adding a module in a blocked namespace did not return a blocked error (%v)", err)
+	}
+	modules, err := dataAPI.ListModules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 0 {
+		t.Fatalf("A module in a blocked namespace was added: %v", modules)
+	}
+}
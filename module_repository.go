@@ -0,0 +1,56 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// moduleRepo describes where the source code of a module is located.
+type moduleRepo struct {
+	addr vcs.RepositoryAddr
+	// subdirectory is the directory of the module in the repository, or empty for the repository root.
+	subdirectory string
+}
+
+// moduleFS returns the module directory of the working copy.
+func (r moduleRepo) moduleFS(workingCopy vcs.WorkingCopy) (fs.ReadDirFS, error) {
+	if r.subdirectory == "" {
+		return workingCopy, nil
+	}
+	sub, err := fs.Sub(workingCopy, r.subdirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open module directory %s (%w)", r.subdirectory, err)
+	}
+	readDirFS, ok := sub.(fs.ReadDirFS)
+	if !ok {
+		return nil, fmt.Errorf("the module directory %s cannot be listed", r.subdirectory)
+	}
+	return readDirFS, nil
+}
+
+// getModuleRepo returns the repository of a module. Unless the module metadata has a custom repository, the
+// repository is named terraform-<target system>-<name> in the organization matching the namespace.
+func (m api) getModuleRepo(moduleAddr module.Addr, moduleMetadata module.Metadata) (moduleRepo, error) {
+	result := moduleRepo{
+		addr: vcs.RepositoryAddr{
+			Org:  vcs.OrganizationAddr(moduleAddr.Namespace),
+			Name: "terraform-" + moduleAddr.TargetSystem + "-" + moduleAddr.Name,
+		},
+		subdirectory: moduleMetadata.TagMapping.GetSubdirectory(),
+	}
+	if moduleMetadata.CustomRepository == "" {
+		return result, nil
+	}
+	addr, err := m.vcsClient.ParseRepositoryAddr(moduleMetadata.CustomRepository)
+	if err != nil {
+		return moduleRepo{}, fmt.Errorf("failed to parse custom repository %s (%w)", moduleMetadata.CustomRepository, err)
+	}
+	result.addr = addr
+	return result, nil
+}
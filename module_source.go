@@ -0,0 +1,104 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func (m api) SetModuleSource(ctx context.Context, moduleAddr module.Addr, customRepository string, tagMapping *module.TagMapping) (ModuleUpdateResult, error) {
+	if err := moduleAddr.Validate(); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if tagMapping != nil {
+		if err := tagMapping.Validate(); err != nil {
+			return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+	}
+	if err := m.checkModuleBlocklist(ctx, moduleAddr); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	exists := true
+	previousMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		var notFoundError *metadata.ModuleNotFoundError
+		if !errors.As(err, &notFoundError) {
+			return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+		exists = false
+		previousMetadata = module.Metadata{
+			Versions: module.VersionList{},
+		}
+	}
+
+	moduleMetadata := previousMetadata
+	moduleMetadata.CustomRepository = customRepository
+	moduleMetadata.TagMapping = tagMapping
+	if _, err := m.getModuleRepo(moduleAddr, moduleMetadata); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if moduleMetadata.CustomRepository != previousMetadata.CustomRepository || !moduleMetadata.TagMapping.Equals(previousMetadata.TagMapping) {
+		moduleMetadata.Versions = versionsForNewSource(previousMetadata.Versions)
+	}
+	if err := m.dataAPI.PutModule(ctx, moduleAddr, moduleMetadata); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	result, err := m.UpdateModule(ctx, moduleAddr)
+	if err != nil {
+		// Restore the previous source so the module is not left with versions from neither source.
+		var restoreErr error
+		if exists {
+			restoreErr = m.dataAPI.PutModule(ctx, moduleAddr, previousMetadata)
+		} else {
+			restoreErr = m.dataAPI.DeleteModule(ctx, moduleAddr)
+		}
+		if restoreErr != nil {
+			m.config.Logger.Warn(ctx, "Failed to restore the previous source of %s (%v)", moduleAddr, restoreErr)
+		}
+		return ModuleUpdateResult{}, err
+	}
+	return result, nil
+}
+
+// versionsForNewSource returns the versions to keep when the source of a module changes. Only the versions that were
+// yanked or deprecated are kept, so they keep their status if the new source has them too. The details read from
+// the previous source are removed, so they are read again from the new source.
+func versionsForNewSource(versions module.VersionList) module.VersionList {
+	result := module.VersionList{}
+	for _, ver := range versions {
+		if ver.Yanked == nil && ver.Deprecated == nil {
+			continue
+		}
+		result = append(result, module.Version{
+			Version:    ver.Version,
+			Deprecated: ver.Deprecated,
+			Yanked:     ver.Yanked,
+		})
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+// TestSetModuleSource tests that a module can be added from a repository on a different VCS host that contains
+// several modules, and that the source can be switched back to the repository matching the module address.
+func TestSetModuleSource(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	monorepo := vcs.RepositoryAddr{
+		Org:  "other",
+		Name: "modules",
+	}
+	defaultVCS := fakevcs.New()
+	otherVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(defaultVCS, dataAPI, libregistry.WithVCSClient("git.example.com", otherVCS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateOrganization(monorepo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateRepository(monorepo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []vcs.VersionNumber{"vpc/v1.0.0", "vpc/v1.1.0", "s3/v2.0.0"} {
+		if err := otherVCS.CreateVersion(monorepo, tag, os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	customRepository := "git.example.com/" + monorepo.String()
+	tagMapping := &module.TagMapping{
+		Prefix:       "vpc/",
+		Subdirectory: "vpc",
+	}
+	if _, err := registry.SetModuleSource(ctx, moduleAddr, customRepository, tagMapping); err != nil {
+		t.Fatal(err)
+	}
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedMetadata.CustomRepository != customRepository || !storedMetadata.TagMapping.Equals(tagMapping) {
+		t.Fatalf("Incorrect source stored: %s %v", storedMetadata.CustomRepository, storedMetadata.TagMapping)
+	}
+	if len(storedMetadata.Versions) != 2 || storedMetadata.Versions[0].Tag != "vpc/v1.1.0" {
+		t.Fatalf("Incorrect versions: %v", storedMetadata.Versions)
+	}
+
+	repo := moduleAddr.ToRepositoryAddr()
+	if err := defaultVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaultVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaultVCS.CreateVersion(repo, "v3.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.SetModuleSource(ctx, moduleAddr, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	storedMetadata, err = dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedMetadata.CustomRepository != "" || storedMetadata.TagMapping != nil {
+		t.Fatalf("The source was not reset: %s %v", storedMetadata.CustomRepository, storedMetadata.TagMapping)
+	}
+	if len(storedMetadata.Versions) != 1 || storedMetadata.Versions[0].Version != "v3.0.0" || storedMetadata.Versions[0].Tag != "" {
+		t.Fatalf("Incorrect versions: %v", storedMetadata.Versions)
+	}
+}
+
+// TestSetModuleSourceInvalid tests that an invalid tag mapping or custom repository is rejected without storing the
+// module.
+func TestSetModuleSourceInvalid(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "vpc",
+		TargetSystem: "aws",
+	}
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(fakevcs.New(), dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var invalidTagMapping *module.InvalidTagMappingError
+	if _, err := registry.SetModuleSource(ctx, moduleAddr, "", &module.TagMapping{Prefix: "vpc/", Pattern: "vpc/(?P<version>.+)"}); !errors.As(err, &invalidTagMapping) {
+		t.Fatalf("Incorrect error returned for an invalid tag mapping: %v", err)
+	}
+	if _, err := registry.SetModuleSource(ctx, moduleAddr, "not a repository", nil); err == nil {
+		t.Fatalf("No error returned for an invalid custom repository.")
+	}
+	var notFound *metadata.ModuleNotFoundError
+	if _, err := dataAPI.GetModule(ctx, moduleAddr, false); !errors.As(err, &notFound) {
+		t.Fatalf("The module was stored despite the invalid source: %v", err)
+	}
+}
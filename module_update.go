@@ -6,86 +6,250 @@ package libregistry
 import (
 	"context"
 	"errors"
+	"net/url"
 
+	"github.com/opentofu/libregistry/license"
 	"github.com/opentofu/libregistry/metadata"
 	"github.com/opentofu/libregistry/types/module"
 	"github.com/opentofu/libregistry/vcs"
 )
 
-func (m api) UpdateModule(ctx context.Context, moduleAddr module.Addr) error {
+func (m api) UpdateModule(ctx context.Context, moduleAddr module.Addr) (ModuleUpdateResult, error) {
 	if err := moduleAddr.Validate(); err != nil {
-		return &ModuleUpdateFailedError{
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+	if err := m.checkModuleBlocklist(ctx, moduleAddr); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
 			moduleAddr,
 			err,
 		}
 	}
 
-	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr)
+	moduleMetadata, err := m.dataAPI.GetModule(ctx, moduleAddr, false)
 	if err != nil {
 		var notFoundError *metadata.ModuleNotFoundError
 		if !errors.As(err, &notFoundError) {
-			return &ModuleUpdateFailedError{
+			return ModuleUpdateResult{}, &ModuleUpdateFailedError{
 				moduleAddr,
 				err,
 			}
 		}
 		moduleMetadata = module.Metadata{}
 	}
-
-	previousSize := len(moduleMetadata.Versions)
-	tags, err := m.vcsClient.ListLatestTags(ctx, getModuleRepo(moduleAddr))
+	if moduleMetadata.TagMapping != nil {
+		if err := moduleMetadata.TagMapping.Validate(); err != nil {
+			return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+				moduleAddr,
+				err,
+			}
+		}
+	}
+	repo, err := m.getModuleRepo(moduleAddr, moduleMetadata)
 	if err != nil {
-		return &ModuleUpdateFailedError{
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
 			moduleAddr,
 			err,
 		}
 	}
-	var newVersions module.VersionList
-	for _, tag := range tags {
-		ver, err := module.VersionFromVCS(tag.VersionNumber)
-		if err != nil {
-			continue
+
+	result := ModuleUpdateResult{
+		Module: moduleAddr,
+	}
+	existingVersions := map[module.VersionNumber]module.Version{}
+	for _, ver := range moduleMetadata.Versions {
+		existingVersions[ver.Version.Normalize()] = ver
+	}
+	tagVersions := map[module.VersionNumber]vcs.Version{}
+
+	previousSize := len(moduleMetadata.Versions)
+	tags, err := m.vcsClient.ListLatestTags(ctx, repo.addr)
+	if err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
 		}
-		newVersions = append(newVersions, module.Version{
-			Version: ver,
-		})
 	}
+	newVersions := m.versionsFromTags(ctx, moduleMetadata.TagMapping, tags, existingVersions, tagVersions)
 	moduleMetadata.Versions = moduleMetadata.Versions.Merge(newVersions)
 
 	if len(moduleMetadata.Versions) == previousSize+len(tags) {
 		// No overlap found, do the full query:
-		tags, err = m.vcsClient.ListAllTags(ctx, getModuleRepo(moduleAddr))
+		result.FullResync = true
+		if previousSize == 0 {
+			result.FullResyncReason = "the module has no stored versions yet"
+		} else {
+			result.FullResyncReason = "none of the latest tags matched a stored version"
+		}
+		tags, err = m.vcsClient.ListAllTags(ctx, repo.addr)
 		if err != nil {
-			return &ModuleUpdateFailedError{
+			return ModuleUpdateResult{}, &ModuleUpdateFailedError{
 				moduleAddr,
 				err,
 			}
 		}
-		newVersions = nil
-		for _, tag := range tags {
-			ver, err := module.VersionFromVCS(tag.VersionNumber)
+		moduleMetadata.Versions = m.versionsFromTags(ctx, moduleMetadata.TagMapping, tags, existingVersions, tagVersions)
+		// Keep yanked versions even if their tag is gone, so they stay yanked if the tag is pushed again.
+		for versionNumber, ver := range existingVersions {
+			if _, ok := tagVersions[versionNumber]; !ok && ver.Yanked != nil {
+				moduleMetadata.Versions = append(moduleMetadata.Versions, ver)
+			}
+		}
+		moduleMetadata.Versions.Sort()
+	}
+
+	if !result.FullResync {
+		// The lightweight tag listing may not include the commit SHA, so resolve it for the new tags only.
+		for i, ver := range moduleMetadata.Versions {
+			if _, ok := existingVersions[ver.Version.Normalize()]; ok || ver.Commit != "" {
+				continue
+			}
+			tag, ok := tagVersions[ver.Version.Normalize()]
+			if !ok {
+				continue
+			}
+			tag, err = m.vcsClient.GetTagVersion(ctx, repo.addr, tag.VersionNumber)
 			if err != nil {
+				return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+					moduleAddr,
+					err,
+				}
+			}
+			tagVersions[ver.Version.Normalize()] = tag
+			moduleMetadata.Versions[i] = fillVersionFromTag(ver, tag)
+		}
+	}
+
+	if err := m.fillSourceURLs(ctx, repo, moduleMetadata.Versions, tagVersions); err != nil {
+		return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+			moduleAddr,
+			err,
+		}
+	}
+
+	if m.config.DetectLicenses {
+		for i, ver := range moduleMetadata.Versions {
+			if _, ok := existingVersions[ver.Version.Normalize()]; ok {
 				continue
 			}
-			newVersions = append(newVersions, module.Version{
-				Version: ver,
-			})
+			licenses, err := m.detectLicenses(ctx, repo, tagVersions[ver.Version.Normalize()].VersionNumber)
+			if err != nil {
+				return ModuleUpdateResult{}, &ModuleUpdateFailedError{
+					moduleAddr,
+					err,
+				}
+			}
+			moduleMetadata.Versions[i].Licenses = licenses
 		}
-		moduleMetadata.Versions = newVersions
 	}
 
 	if err := m.dataAPI.PutModule(ctx, moduleAddr, moduleMetadata); err != nil {
-		return &ModuleAddFailedError{
+		return ModuleUpdateResult{}, &ModuleAddFailedError{
 			moduleAddr,
 			err,
 		}
 	}
+	result.fillVersionChanges(existingVersions, moduleMetadata.Versions)
+	return result, nil
+}
+
+// versionsFromTags converts the VCS tags to module versions using the tag mapping, which may be nil. Versions that
+// are already known keep their stored details, but missing details are filled in from the tag. The tags are recorded
+// in tagVersions by version number.
+func (m api) versionsFromTags(ctx context.Context, tagMapping *module.TagMapping, tags []vcs.Version, existingVersions map[module.VersionNumber]module.Version, tagVersions map[module.VersionNumber]vcs.Version) module.VersionList {
+	var result module.VersionList
+	for _, tag := range tags {
+		ver, err := tagMapping.VersionFromTag(tag.VersionNumber)
+		if err != nil {
+			continue
+		}
+		tagVersions[ver.Normalize()] = tag
+		newVersion := module.Version{
+			Version: ver,
+		}
+		if existing, ok := existingVersions[ver.Normalize()]; ok {
+			if existing.Commit != "" && tag.Commit != "" && existing.Commit != tag.Commit {
+				m.config.Logger.Warn(ctx, "The tag %s now points to commit %s instead of %s, keeping the original commit.", tag.VersionNumber, tag.Commit, existing.Commit)
+			}
+			newVersion = existing
+			newVersion.Version = ver
+		}
+		result = append(result, fillVersionFromTag(newVersion, tag))
+	}
+	return result
+}
+
+// fillVersionFromTag fills in the tag, publish time and commit from the tag if they are not known yet.
+func fillVersionFromTag(ver module.Version, tag vcs.Version) module.Version {
+	if ver.Tag == "" && string(tag.VersionNumber) != string(ver.Version) {
+		ver.Tag = tag.VersionNumber
+	}
+	if ver.Published == nil && !tag.Created.IsZero() {
+		published := tag.Created.UTC()
+		ver.Published = &published
+	}
+	if ver.Commit == "" {
+		ver.Commit = tag.Commit
+	}
+	return ver
+}
+
+// fillSourceURLs sets the source URL on all versions that have none and whose tag is known. If the module is in a
+// subdirectory, the source URL points to it. If the VCS system does not provide web access, the source URLs are left
+// empty.
+func (m api) fillSourceURLs(ctx context.Context, repo moduleRepo, versions module.VersionList, tagVersions map[module.VersionNumber]vcs.Version) error {
+	browseURL := ""
+	for i, ver := range versions {
+		if ver.SourceURL != "" {
+			continue
+		}
+		tag, ok := tagVersions[ver.Version.Normalize()]
+		if !ok {
+			continue
+		}
+		if browseURL == "" {
+			var err error
+			browseURL, err = m.vcsClient.GetRepositoryBrowseURL(ctx, repo.addr)
+			if err != nil {
+				var noWebAccess *vcs.NoWebAccessError
+				if errors.As(err, &noWebAccess) {
+					return nil
+				}
+				return err
+			}
+		}
+		subdirectory := ""
+		if repo.subdirectory != "" {
+			subdirectory = "//" + repo.subdirectory
+		}
+		versions[i].SourceURL = "git::" + browseURL + subdirectory + "?ref=" + url.QueryEscape(string(tag.VersionNumber))
+	}
 	return nil
 }
 
-func getModuleRepo(module module.Addr) vcs.RepositoryAddr {
-	return vcs.RepositoryAddr{
-		Org:  vcs.OrganizationAddr(module.Namespace),
-		Name: "terraform-" + module.TargetSystem + "-" + module.Name,
+// detectLicenses checks out the specified version and returns the SPDX IDs of the licenses found. If the module is in
+// a subdirectory, the license files in the subdirectory take precedence over the ones in the repository root.
+func (m api) detectLicenses(ctx context.Context, repo moduleRepo, version vcs.VersionNumber) ([]string, error) {
+	workingCopy, err := m.vcsClient.Checkout(ctx, repo.addr, version)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := workingCopy.Close(); err != nil {
+			m.config.Logger.Warn(ctx, "Failed to close working copy of %s version %s (%v)", repo.addr, version, err)
+		}
+	}()
+	if repo.subdirectory != "" {
+		moduleFS, err := repo.moduleFS(workingCopy)
+		if err != nil {
+			return nil, err
+		}
+		licenses, err := license.DetectFS(moduleFS)
+		if err != nil || len(licenses) > 0 {
+			return licenses, err
+		}
 	}
+	return license.Detect(workingCopy)
 }
@@ -0,0 +1,79 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"strings"
+
+	"github.com/opentofu/libregistry/types/module"
+)
+
+// ModuleUpdateResult describes the changes UpdateModule made to the versions of a module. The version lists are
+// sorted newest first.
+type ModuleUpdateResult struct {
+	Module module.Addr
+	// Added lists the versions that were not stored before the update.
+	Added []module.VersionNumber
+	// Removed lists the versions that were stored before the update, but whose tags no longer exist.
+	Removed []module.VersionNumber
+	// Unchanged lists the versions that were stored before and after the update.
+	Unchanged []module.VersionNumber
+	// FullResync is true if the version list was rebuilt from the full tag list (ListAllTags) instead of only
+	// merging the latest tags. Only a full resync can remove versions.
+	FullResync bool
+	// FullResyncReason explains why a full resync was necessary. It is empty if FullResync is false.
+	FullResyncReason string
+}
+
+// Changed returns true if versions were added or removed.
+func (r ModuleUpdateResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// Summary returns a single line, human-readable description of the changes, e.g. for a commit message.
+func (r ModuleUpdateResult) Summary() string {
+	if !r.Changed() {
+		return "No changes to " + r.Module.String()
+	}
+	var parts []string
+	if len(r.Added) > 0 {
+		parts = append(parts, "added "+joinVersions(r.Added))
+	}
+	if len(r.Removed) > 0 {
+		parts = append(parts, "removed "+joinVersions(r.Removed))
+	}
+	return r.Module.String() + ": " + strings.Join(parts, ", ")
+}
+
+func joinVersions(versions []module.VersionNumber) string {
+	parts := make([]string, len(versions))
+	for i, ver := range versions {
+		parts[i] = string(ver)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fillVersionChanges compares the versions stored before the update to the new versions.
+func (r *ModuleUpdateResult) fillVersionChanges(existingVersions map[module.VersionNumber]module.Version, newVersions module.VersionList) {
+	seen := make(map[module.VersionNumber]struct{}, len(newVersions))
+	for _, ver := range newVersions {
+		versionNumber := ver.Version.Normalize()
+		seen[versionNumber] = struct{}{}
+		if _, ok := existingVersions[versionNumber]; ok {
+			r.Unchanged = append(r.Unchanged, ver.Version)
+		} else {
+			r.Added = append(r.Added, ver.Version)
+		}
+	}
+	var removed module.VersionList
+	for versionNumber, ver := range existingVersions {
+		if _, ok := seen[versionNumber]; !ok {
+			removed = append(removed, ver)
+		}
+	}
+	removed.Sort()
+	for _, ver := range removed {
+		r.Removed = append(r.Removed, ver.Version)
+	}
+}
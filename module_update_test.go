@@ -5,10 +5,12 @@ package libregistry_test
 
 import (
 	"context"
+	"errors"
 	"io/fs"
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/opentofu/libregistry"
 	"github.com/opentofu/libregistry/metadata"
@@ -72,11 +74,11 @@ func TestUpdateModuleBackfill(t *testing.T) {
 		}
 	}
 
-	if err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
 		t.Fatal(err)
 	}
 
-	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr)
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -93,3 +95,444 @@ func TestUpdateModuleBackfill(t *testing.T) {
 		j++
 	}
 }
+
+// TestUpdateModuleVersionMetadata tests that the publish time and commit SHA are recorded for each version and are
+// preserved across updates.
+func TestUpdateModuleVersionMetadata(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	inMemoryVCS, err := fakevcs.NewWithOpts(fakevcs.WithTimeSource(func() time.Time {
+		return now
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if err := inMemoryVCS.CreateVersion(repo, "v1.1.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 2 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	for i, expectedPublished := range []time.Time{now, now.Add(-24 * time.Hour)} {
+		ver := storedMetadata.Versions[i]
+		if ver.Published == nil || !ver.Published.Equal(expectedPublished) {
+			t.Fatalf("Incorrect publish time for %s: %v", ver.Version, ver.Published)
+		}
+		tag, err := inMemoryVCS.GetTagVersion(ctx, repo, ver.Version.ToVCSVersion())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ver.Commit == "" || ver.Commit != tag.Commit {
+			t.Fatalf("Incorrect commit for %s: %s", ver.Version, ver.Commit)
+		}
+		if ver.SourceURL != "" {
+			t.Fatalf("Source URL set despite the VCS not providing web access: %s", ver.SourceURL)
+		}
+	}
+}
+
+// commitlessVCS returns the latest tags without commit SHAs and can refuse to list all tags.
+type commitlessVCS struct {
+	fakevcs.VCSClient
+	failListAllTags *bool
+}
+
+func (c commitlessVCS) ListLatestTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	tags, err := c.VCSClient.ListLatestTags(ctx, repository)
+	for i := range tags {
+		tags[i].Commit = ""
+	}
+	return tags, err
+}
+
+func (c commitlessVCS) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	if *c.failListAllTags {
+		return nil, errors.New("ListAllTags called")
+	}
+	return c.VCSClient.ListAllTags(ctx, repository)
+}
+
+// TestUpdateModuleResolvesNewCommits tests that the commits of new tags are resolved individually if the latest tag
+// listing does not include them, instead of listing all tags.
+func TestUpdateModuleResolvesNewCommits(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	failListAllTags := false
+	registry, err := libregistry.New(commitlessVCS{inMemoryVCS, &failListAllTags}, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	failListAllTags = true
+	if err := inMemoryVCS.CreateVersion(repo, "v1.1.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 2 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	for _, ver := range storedMetadata.Versions {
+		tag, err := inMemoryVCS.GetTagVersion(ctx, repo, ver.Version.ToVCSVersion())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ver.Commit == "" || ver.Commit != tag.Commit {
+			t.Fatalf("Incorrect commit for %s: %s", ver.Version, ver.Commit)
+		}
+	}
+}
+
+type browsableVCS struct {
+	fakevcs.VCSClient
+}
+
+func (b browsableVCS) GetRepositoryBrowseURL(_ context.Context, repository vcs.RepositoryAddr) (string, error) {
+	return "https://example.com/" + string(repository.Org) + "/" + repository.Name, nil
+}
+
+// TestUpdateModuleSourceURL tests that the source URL is filled in when the VCS provides web access.
+func TestUpdateModuleSourceURL(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(browsableVCS{inMemoryVCS}, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sourceURL := storedMetadata.Versions[0].SourceURL; sourceURL != "git::https://example.com/test/terraform-iam-aws?ref=v1.0.0" {
+		t.Fatalf("Incorrect source URL: %s", sourceURL)
+	}
+}
+
+// TestUpdateModuleKeepsYanked tests that yanked versions stay yanked when the tags are synchronized again, even if
+// the full tag list is queried.
+func TestUpdateModuleKeepsYanked(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddModule(ctx, repo.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.YankModuleVersion(ctx, moduleAddr, "v1.0.0", "Broken release"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add enough versions to force a full resync.
+	for i := 1; i <= 10; i++ {
+		if err := inMemoryVCS.CreateVersion(repo, vcs.VersionNumber("v1.1."+strconv.Itoa(i)), os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 11 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	for _, ver := range storedMetadata.Versions {
+		if ver.Version == "v1.0.0" {
+			if ver.Yanked == nil || ver.Yanked.Reason != "Broken release" {
+				t.Fatalf("The yanked version is no longer yanked: %v", ver)
+			}
+		} else if ver.Yanked != nil {
+			t.Fatalf("Version %s was yanked incorrectly", ver.Version)
+		}
+	}
+}
+
+// TestUpdateModuleTagMapping tests that only the tags matching the tag mapping are turned into versions and that the
+// source URL points to the module subdirectory.
+func TestUpdateModuleTagMapping(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(browsableVCS{inMemoryVCS}, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []vcs.VersionNumber{"modules/vpc/v1.0.0", "modules/vpc/v1.1.0", "modules/s3/v2.0.0", "v3.0.0"} {
+		if err := inMemoryVCS.CreateVersion(repo, tag, os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: module.VersionList{},
+		TagMapping: &module.TagMapping{
+			Pattern:      `modules/vpc/(?P<version>.+)`,
+			Subdirectory: "modules/vpc",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 2 {
+		t.Fatalf("Incorrect number of versions: %d", len(storedMetadata.Versions))
+	}
+	ver := storedMetadata.Versions[0]
+	if ver.Version != "v1.1.0" || ver.Tag != "modules/vpc/v1.1.0" {
+		t.Fatalf("Incorrect version or tag: %s (%s)", ver.Version, ver.Tag)
+	}
+	if ver.SourceURL != "git::https://example.com/test/terraform-iam-aws//modules/vpc?ref=modules%2Fvpc%2Fv1.1.0" {
+		t.Fatalf("Incorrect source URL: %s", ver.SourceURL)
+	}
+}
+
+// TestUpdateModuleCustomRepository tests that a module with a custom repository on a different VCS host is updated
+// from that repository using the VCS client registered for the host.
+func TestUpdateModuleCustomRepository(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := vcs.RepositoryAddr{
+		Org:  "other",
+		Name: "iam-module",
+	}
+	defaultVCS := fakevcs.New()
+	otherVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(defaultVCS, dataAPI, libregistry.WithVCSClient("git.example.com", otherVCS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		CustomRepository: "git.example.com/" + repo.String(),
+		Versions:         module.VersionList{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+		t.Fatal(err)
+	}
+	storedMetadata, err := dataAPI.GetModule(ctx, moduleAddr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storedMetadata.Versions) != 1 || storedMetadata.Versions[0].Version != "v1.0.0" {
+		t.Fatalf("Incorrect versions: %v", storedMetadata.Versions)
+	}
+	if storedMetadata.CustomRepository != "git.example.com/"+repo.String() {
+		t.Fatalf("The custom repository was not kept: %s", storedMetadata.CustomRepository)
+	}
+}
+
+// TestUpdateModuleResult tests that the result of UpdateModule lists the added and removed versions and reports the
+// full resync.
+func TestUpdateModuleResult(t *testing.T) {
+	moduleAddr := module.Addr{
+		Namespace:    "test",
+		Name:         "aws",
+		TargetSystem: "iam",
+	}
+	repo := moduleAddr.ToRepositoryAddr()
+	inMemoryVCS := fakevcs.New()
+	ctx := context.Background()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := inMemoryVCS.CreateVersion(repo, vcs.VersionNumber("v1.0."+strconv.Itoa(i)), os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// v0.9.0 has no tag, so the full resync removes it.
+	if err := dataAPI.PutModule(ctx, moduleAddr, module.Metadata{
+		Versions: module.VersionList{{Version: "v0.9.0"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := registry.UpdateModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.FullResync || result.FullResyncReason == "" {
+		t.Fatalf("The full resync was not reported: %v", result)
+	}
+	if len(result.Added) != 6 || result.Added[0] != "v1.0.5" {
+		t.Fatalf("Incorrect added versions: %v", result.Added)
+	}
+	if len(result.Removed) != 1 || result.Removed[0] != "v0.9.0" {
+		t.Fatalf("Incorrect removed versions: %v", result.Removed)
+	}
+	if len(result.Unchanged) != 0 {
+		t.Fatalf("Incorrect unchanged versions: %v", result.Unchanged)
+	}
+
+	result, err = registry.UpdateModule(ctx, moduleAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed() || result.FullResync || len(result.Unchanged) != 6 {
+		t.Fatalf("Incorrect result for an update without changes: %v", result)
+	}
+	if result.Summary() != "No changes to "+moduleAddr.String() {
+		t.Fatalf("Incorrect summary: %s", result.Summary())
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package moduleanalysis extracts the interface of a module (variables, outputs, required providers and resources)
+// from a checked out module version.
+package moduleanalysis
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	submodulesDirectory = "modules"
+	examplesDirectory   = "examples"
+)
+
+// Analyze reads the root module, the submodules in the modules/ directory and the examples in the examples/ directory
+// from the working copy. Directories in modules/ and examples/ that contain no OpenTofu files are skipped. If a
+// submodule or example cannot be parsed, the error is recorded in its details and the analysis continues with the
+// rest of the module. Only errors in the root module fail the analysis.
+func Analyze(workingCopy vcs.WorkingCopy) (module.VersionDetails, error) {
+	return AnalyzeFS(workingCopy)
+}
+
+// AnalyzeFS works like Analyze, but reads the module from an arbitrary filesystem.
+func AnalyzeFS(fsys fs.ReadDirFS) (module.VersionDetails, error) {
+	root, err := analyzeDirectory(fsys, ".")
+	if err != nil {
+		return module.VersionDetails{}, err
+	}
+	submodules, err := analyzeSubdirectories(fsys, submodulesDirectory)
+	if err != nil {
+		return module.VersionDetails{}, err
+	}
+	examples, err := analyzeSubdirectories(fsys, examplesDirectory)
+	if err != nil {
+		return module.VersionDetails{}, err
+	}
+	return module.VersionDetails{
+		Root:       root,
+		Submodules: submodules,
+		Examples:   examples,
+	}, nil
+}
+
+func analyzeSubdirectories(fsys fs.ReadDirFS, dir string) ([]module.Details, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory %s (%w)", dir, err)
+	}
+	var result []module.Details
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		subdir := path.Join(dir, entry.Name())
+		files, err := listModuleFiles(fsys, subdir)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		details, err := analyzeDirectory(fsys, subdir)
+		if err != nil {
+			var invalidErr *InvalidModuleFileError
+			if !errors.As(err, &invalidErr) {
+				return nil, err
+			}
+			details = module.Details{
+				Path:  subdir,
+				Error: err.Error(),
+			}
+		}
+		result = append(result, details)
+	}
+	return result, nil
+}
+
+// moduleFileExtensions lists the extensions of the files read from a module directory. A .tofu file takes precedence
+// over the .tf file with the same name, and a .tofu.json file over the .tf.json file with the same name, so modules
+// can ship OpenTofu-specific code next to their Terraform code.
+var moduleFileExtensions = []struct {
+	extension string
+	// overrides is the extension of the files ignored if a file with this extension and the same name exists.
+	overrides string
+}{
+	{".tofu.json", ".tf.json"},
+	{".tf.json", ""},
+	{".tofu", ".tf"},
+	{".tf", ""},
+}
+
+// listModuleFiles returns the .tf, .tf.json, .tofu and .tofu.json files in a directory. Files replaced by a .tofu or
+// .tofu.json file are left out. Override files are skipped since they are typically used for local modifications and
+// do not describe the module interface.
+func listModuleFiles(fsys fs.ReadDirFS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s (%w)", dir, err)
+	}
+	names := map[string]struct{}{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = struct{}{}
+		}
+	}
+	var result []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		for _, ext := range moduleFileExtensions {
+			if !strings.HasSuffix(name, ext.extension) {
+				continue
+			}
+			base := strings.TrimSuffix(name, ext.extension)
+			if base == "override" || strings.HasSuffix(base, "_override") {
+				break
+			}
+			if isOverriddenByTofuFile(names, base, ext.extension) {
+				break
+			}
+			result = append(result, path.Join(dir, name))
+			break
+		}
+	}
+	return result, nil
+}
+
+// isOverriddenByTofuFile checks if a .tofu or .tofu.json file replaces the file with the specified base name and
+// extension.
+func isOverriddenByTofuFile(names map[string]struct{}, base string, extension string) bool {
+	for _, ext := range moduleFileExtensions {
+		if ext.overrides == extension {
+			_, ok := names[base+ext.extension]
+			return ok
+		}
+	}
+	return false
+}
+
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+	},
+}
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+	},
+}
+
+var variableBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "type"},
+		{Name: "description"},
+		{Name: "default"},
+		{Name: "sensitive"},
+	},
+}
+
+var outputBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "description"},
+		{Name: "sensitive"},
+	},
+}
+
+func analyzeDirectory(fsys fs.ReadDirFS, dir string) (module.Details, error) {
+	files, err := listModuleFiles(fsys, dir)
+	if err != nil {
+		return module.Details{}, err
+	}
+
+	details := module.Details{
+		Path: dir,
+	}
+	parser := hclparse.NewParser()
+	providers := map[string]*module.RequiredProvider{}
+	for _, file := range files {
+		contents, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return module.Details{}, fmt.Errorf("failed to read %s (%w)", file, err)
+		}
+		var parsed *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(file, ".json") {
+			parsed, diags = parser.ParseJSON(contents, file)
+		} else {
+			parsed, diags = parser.ParseHCL(contents, file)
+		}
+		if diags.HasErrors() {
+			return module.Details{}, &InvalidModuleFileError{File: file, Cause: diags}
+		}
+		content, _, diags := parsed.Body.PartialContent(fileSchema)
+		if diags.HasErrors() {
+			return module.Details{}, &InvalidModuleFileError{File: file, Cause: diags}
+		}
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "terraform":
+				if err := readRequiredProviders(block, providers); err != nil {
+					return module.Details{}, &InvalidModuleFileError{File: file, Cause: err}
+				}
+			case "variable":
+				variable, err := readVariable(block, parsed.Bytes)
+				if err != nil {
+					return module.Details{}, &InvalidModuleFileError{File: file, Cause: err}
+				}
+				details.Variables = append(details.Variables, variable)
+			case "output":
+				output, err := readOutput(block)
+				if err != nil {
+					return module.Details{}, &InvalidModuleFileError{File: file, Cause: err}
+				}
+				details.Outputs = append(details.Outputs, output)
+			case "resource", "data":
+				mode := "managed"
+				if block.Type == "data" {
+					mode = "data"
+				}
+				details.Resources = append(details.Resources, module.Resource{
+					Mode: mode,
+					Type: block.Labels[0],
+					Name: block.Labels[1],
+				})
+			}
+		}
+	}
+
+	for _, provider := range providers {
+		details.RequiredProviders = append(details.RequiredProviders, *provider)
+	}
+	sort.SliceStable(details.Variables, func(i, j int) bool {
+		return details.Variables[i].Name < details.Variables[j].Name
+	})
+	sort.SliceStable(details.Outputs, func(i, j int) bool {
+		return details.Outputs[i].Name < details.Outputs[j].Name
+	})
+	sort.SliceStable(details.RequiredProviders, func(i, j int) bool {
+		return details.RequiredProviders[i].Name < details.RequiredProviders[j].Name
+	})
+	sort.SliceStable(details.Resources, func(i, j int) bool {
+		a, b := details.Resources[i], details.Resources[j]
+		if a.Mode != b.Mode {
+			return a.Mode < b.Mode
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return details, nil
+}
+
+// readRequiredProviders collects the provider requirements from a terraform block. Requirements for the same provider
+// from several blocks are merged.
+func readRequiredProviders(block *hcl.Block, providers map[string]*module.RequiredProvider) error {
+	content, _, diags := block.Body.PartialContent(terraformBlockSchema)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, requiredProvidersBlock := range content.Blocks {
+		attrs, diags := requiredProvidersBlock.Body.JustAttributes()
+		if diags.HasErrors() {
+			return diags
+		}
+		for name, attr := range attrs {
+			provider, ok := providers[name]
+			if !ok {
+				provider = &module.RequiredProvider{Name: name}
+				providers[name] = provider
+			}
+
+			pairs, diags := hcl.ExprMap(attr.Expr)
+			if diags.HasErrors() {
+				// Legacy syntax: the attribute only contains a version constraint.
+				constraint, err := readString(attr.Expr)
+				if err != nil {
+					return fmt.Errorf("invalid provider requirement for %s (%w)", name, err)
+				}
+				provider.VersionConstraints = appendConstraint(provider.VersionConstraints, constraint)
+				continue
+			}
+			for _, pair := range pairs {
+				key := hcl.ExprAsKeyword(pair.Key)
+				if key == "" {
+					keyValue, err := readString(pair.Key)
+					if err != nil {
+						continue
+					}
+					key = keyValue
+				}
+				switch key {
+				case "source":
+					source, err := readString(pair.Value)
+					if err != nil {
+						return fmt.Errorf("invalid source for provider %s (%w)", name, err)
+					}
+					provider.Source = source
+				case "version":
+					constraint, err := readString(pair.Value)
+					if err != nil {
+						return fmt.Errorf("invalid version constraint for provider %s (%w)", name, err)
+					}
+					provider.VersionConstraints = appendConstraint(provider.VersionConstraints, constraint)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func appendConstraint(constraints []string, constraint string) []string {
+	if constraint == "" {
+		return constraints
+	}
+	for _, c := range constraints {
+		if c == constraint {
+			return constraints
+		}
+	}
+	return append(constraints, constraint)
+}
+
+func readVariable(block *hcl.Block, source []byte) (module.Variable, error) {
+	content, _, diags := block.Body.PartialContent(variableBlockSchema)
+	if diags.HasErrors() {
+		return module.Variable{}, diags
+	}
+	variable := module.Variable{
+		Name:     block.Labels[0],
+		Required: true,
+	}
+	if attr, ok := content.Attributes["type"]; ok {
+		variable.Type = readTypeExpression(attr.Expr, source)
+	}
+	if attr, ok := content.Attributes["description"]; ok {
+		description, err := readString(attr.Expr)
+		if err != nil {
+			return module.Variable{}, fmt.Errorf("invalid description for variable %s (%w)", variable.Name, err)
+		}
+		variable.Description = description
+	}
+	if attr, ok := content.Attributes["sensitive"]; ok {
+		sensitive, err := readBool(attr.Expr)
+		if err != nil {
+			return module.Variable{}, fmt.Errorf("invalid sensitive flag for variable %s (%w)", variable.Name, err)
+		}
+		variable.Sensitive = sensitive
+	}
+	if attr, ok := content.Attributes["default"]; ok {
+		variable.Required = false
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return module.Variable{}, fmt.Errorf("invalid default value for variable %s (%w)", variable.Name, diags)
+		}
+		marshalled, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+		if err != nil {
+			return module.Variable{}, fmt.Errorf("failed to marshal default value for variable %s (%w)", variable.Name, err)
+		}
+		variable.Default = marshalled
+	}
+	return variable, nil
+}
+
+// readTypeExpression returns the type constraint of a variable as written in the source. In JSON files the type is
+// written as a string, which is returned as-is.
+func readTypeExpression(expr hcl.Expression, source []byte) string {
+	if value, diags := expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() && !value.IsNull() {
+		return value.AsString()
+	}
+	rng := expr.Range()
+	if rng.Start.Byte < 0 || rng.End.Byte > len(source) || rng.Start.Byte > rng.End.Byte {
+		return ""
+	}
+	return string(source[rng.Start.Byte:rng.End.Byte])
+}
+
+func readOutput(block *hcl.Block) (module.Output, error) {
+	content, _, diags := block.Body.PartialContent(outputBlockSchema)
+	if diags.HasErrors() {
+		return module.Output{}, diags
+	}
+	output := module.Output{
+		Name: block.Labels[0],
+	}
+	if attr, ok := content.Attributes["description"]; ok {
+		description, err := readString(attr.Expr)
+		if err != nil {
+			return module.Output{}, fmt.Errorf("invalid description for output %s (%w)", output.Name, err)
+		}
+		output.Description = description
+	}
+	if attr, ok := content.Attributes["sensitive"]; ok {
+		sensitive, err := readBool(attr.Expr)
+		if err != nil {
+			return module.Output{}, fmt.Errorf("invalid sensitive flag for output %s (%w)", output.Name, err)
+		}
+		output.Sensitive = sensitive
+	}
+	return output, nil
+}
+
+func readString(expr hcl.Expression) (string, error) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	if value.IsNull() {
+		return "", nil
+	}
+	if value.Type() != cty.String || !value.IsKnown() {
+		return "", fmt.Errorf("expected a string, got %s", value.Type().FriendlyName())
+	}
+	return value.AsString(), nil
+}
+
+func readBool(expr hcl.Expression) (bool, error) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return false, diags
+	}
+	if value.IsNull() {
+		return false, nil
+	}
+	if value.Type() != cty.Bool || !value.IsKnown() {
+		return false, fmt.Errorf("expected a bool, got %s", value.Type().FriendlyName())
+	}
+	return value.True(), nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduleanalysis_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/moduleanalysis"
+)
+
+func TestAnalyze(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tf": {Data: []byte(`
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+      configuration_aliases = [aws.secondary]
+    }
+  }
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+data "aws_region" "current" {}
+`)},
+		"variables.tf": {Data: []byte(`
+variable "name" {
+  type        = string
+  description = "Name of the bucket."
+}
+
+variable "tags" {
+  type      = map(string)
+  default   = { env = "test" }
+  sensitive = true
+}
+`)},
+		"outputs.tf.json": {Data: []byte(`{
+  "output": {
+    "arn": {
+      "value": "${aws_s3_bucket.this.arn}",
+      "description": "ARN of the bucket."
+    }
+  }
+}`)},
+		"override.tf": {Data: []byte(`variable "ignored" {}`)},
+		"README.md":   {Data: []byte(`# Test`)},
+		"modules/policy/main.tf": {Data: []byte(`
+variable "bucket" {}
+
+terraform {
+  required_providers {
+    aws = "~> 5.1"
+  }
+}
+`)},
+		"modules/empty/README.md":    {Data: []byte(`# Empty`)},
+		"examples/basic/main.tf":     {Data: []byte(`module "bucket" { source = "../../" }`)},
+		"examples/basic/versions.tf": {Data: []byte(`terraform { required_version = ">= 1.6" }`)},
+	}
+
+	details, err := moduleanalysis.AnalyzeFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to analyze module (%v)", err)
+	}
+
+	root := details.Root
+	if root.Path != "." {
+		t.Fatalf("Incorrect root path: %s", root.Path)
+	}
+	if len(root.Variables) != 2 {
+		t.Fatalf("Incorrect number of variables: %d", len(root.Variables))
+	}
+	if v := root.Variables[0]; v.Name != "name" || v.Type != "string" || !v.Required || v.Description != "Name of the bucket." {
+		t.Fatalf("Incorrect variable: %v", v)
+	}
+	if v := root.Variables[1]; v.Name != "tags" || v.Type != "map(string)" || v.Required || !v.Sensitive || string(v.Default) != `{"env":"test"}` {
+		t.Fatalf("Incorrect variable: %v", v)
+	}
+	if len(root.Outputs) != 1 || root.Outputs[0].Name != "arn" || root.Outputs[0].Description != "ARN of the bucket." {
+		t.Fatalf("Incorrect outputs: %v", root.Outputs)
+	}
+	if len(root.RequiredProviders) != 1 {
+		t.Fatalf("Incorrect number of required providers: %d", len(root.RequiredProviders))
+	}
+	if p := root.RequiredProviders[0]; p.Name != "aws" || p.Source != "hashicorp/aws" || len(p.VersionConstraints) != 1 || p.VersionConstraints[0] != ">= 5.0" {
+		t.Fatalf("Incorrect required provider: %v", p)
+	}
+	if len(root.Resources) != 2 {
+		t.Fatalf("Incorrect number of resources: %d", len(root.Resources))
+	}
+	if r := root.Resources[0]; r.Mode != "data" || r.Type != "aws_region" || r.Name != "current" {
+		t.Fatalf("Incorrect resource: %v", r)
+	}
+	if r := root.Resources[1]; r.Mode != "managed" || r.Type != "aws_s3_bucket" || r.Name != "this" {
+		t.Fatalf("Incorrect resource: %v", r)
+	}
+
+	if len(details.Submodules) != 1 || details.Submodules[0].Path != "modules/policy" {
+		t.Fatalf("Incorrect submodules: %v", details.Submodules)
+	}
+	if p := details.Submodules[0].RequiredProviders; len(p) != 1 || p[0].VersionConstraints[0] != "~> 5.1" {
+		t.Fatalf("Incorrect legacy provider requirement: %v", p)
+	}
+	if len(details.Examples) != 1 || details.Examples[0].Path != "examples/basic" {
+		t.Fatalf("Incorrect examples: %v", details.Examples)
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tf": {Data: []byte(`variable "name" {`)},
+	}
+	_, err := moduleanalysis.AnalyzeFS(fsys)
+	if err == nil {
+		t.Fatalf("Analyzing an invalid module did not fail.")
+	}
+	var invalidErr *moduleanalysis.InvalidModuleFileError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Incorrect error type returned: %T", err)
+	}
+	if invalidErr.File != "main.tf" {
+		t.Fatalf("Incorrect file in error: %s", invalidErr.File)
+	}
+}
+
+func TestAnalyzeTofuFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tf":            {Data: []byte(`variable "terraform" {}`)},
+		"main.tofu":          {Data: []byte(`variable "tofu" {}`)},
+		"outputs.tf.json":    {Data: []byte(`{"output": {"terraform": {"value": "1"}}}`)},
+		"outputs.tofu.json":  {Data: []byte(`{"output": {"tofu": {"value": "1"}}}`)},
+		"extra.tofu":         {Data: []byte(`variable "extra" {}`)},
+		"versions.tf":        {Data: []byte(`variable "versions" {}`)},
+		"tofu_override.tofu": {Data: []byte(`variable "ignored" {}`)},
+	}
+	details, err := moduleanalysis.AnalyzeFS(fsys)
+	if err != nil {
+		t.Fatalf("Failed to analyze module (%v)", err)
+	}
+	var variables []string
+	for _, v := range details.Root.Variables {
+		variables = append(variables, v.Name)
+	}
+	if strings.Join(variables, ",") != "extra,tofu,versions" {
+		t.Fatalf("Incorrect variables: %v", variables)
+	}
+	if len(details.Root.Outputs) != 1 || details.Root.Outputs[0].Name != "tofu" {
+		t.Fatalf("Incorrect outputs: %v", details.Root.Outputs)
+	}
+}
+
+func TestAnalyzeInvalidExample(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tf":                   {Data: []byte(`variable "name" {}`)},
+		"modules/policy/main.tf":    {Data: []byte(`variable "bucket" {}`)},
+		"examples/basic/main.tf":    {Data: []byte(`module "bucket" { source = "../../" }`)},
+		"examples/broken/main.tf":   {Data: []byte(`variable "name" {`)},
+		"examples/complete/main.tf": {Data: []byte(`module "bucket" { source = "../../" }`)},
+	}
+	details, err := moduleanalysis.AnalyzeFS(fsys)
+	if err != nil {
+		t.Fatalf("A broken example failed the analysis (%v)", err)
+	}
+	if len(details.Root.Variables) != 1 || len(details.Submodules) != 1 || details.Submodules[0].Error != "" {
+		t.Fatalf("Incorrect root module or submodules: %v %v", details.Root, details.Submodules)
+	}
+	if len(details.Examples) != 3 {
+		t.Fatalf("Incorrect examples: %v", details.Examples)
+	}
+	for _, example := range details.Examples {
+		broken := example.Path == "examples/broken"
+		if broken != (example.Error != "") {
+			t.Fatalf("Incorrect error for %s: %q", example.Path, example.Error)
+		}
+	}
+	if !strings.Contains(details.Examples[1].Error, "examples/broken/main.tf") {
+		t.Fatalf("The error does not name the broken file: %s", details.Examples[1].Error)
+	}
+}
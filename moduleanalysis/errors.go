@@ -0,0 +1,21 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package moduleanalysis
+
+// InvalidModuleFileError indicates that a file in the module could not be parsed.
+type InvalidModuleFileError struct {
+	File  string
+	Cause error
+}
+
+func (i InvalidModuleFileError) Error() string {
+	if i.Cause != nil {
+		return "Invalid module file: " + i.File + " (" + i.Cause.Error() + ")"
+	}
+	return "Invalid module file: " + i.File
+}
+
+func (i InvalidModuleFileError) Unwrap() error {
+	return i.Cause
+}
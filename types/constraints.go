@@ -0,0 +1,184 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types
+
+import (
+	"strings"
+)
+
+// ConstraintOperator is the comparison operator of a single version constraint.
+type ConstraintOperator string
+
+const (
+	ConstraintOperatorEqual              ConstraintOperator = "="
+	ConstraintOperatorNotEqual           ConstraintOperator = "!="
+	ConstraintOperatorGreaterThan        ConstraintOperator = ">"
+	ConstraintOperatorGreaterThanOrEqual ConstraintOperator = ">="
+	ConstraintOperatorLessThan           ConstraintOperator = "<"
+	ConstraintOperatorLessThanOrEqual    ConstraintOperator = "<="
+	ConstraintOperatorPessimistic        ConstraintOperator = "~>"
+)
+
+// constraintOperators lists the operators with the longer operators first so prefixes match correctly.
+var constraintOperators = []ConstraintOperator{
+	ConstraintOperatorNotEqual,
+	ConstraintOperatorGreaterThanOrEqual,
+	ConstraintOperatorLessThanOrEqual,
+	ConstraintOperatorPessimistic,
+	ConstraintOperatorEqual,
+	ConstraintOperatorGreaterThan,
+	ConstraintOperatorLessThan,
+}
+
+// VersionConstraint is a single version constraint, such as ">= 1.2".
+type VersionConstraint struct {
+	Operator ConstraintOperator
+	// Version is the version to compare to. Missing minor and patch versions are filled with zeros.
+	Version SemVer
+	// Components is the number of version components that were specified, from 1 to 3. It determines the upper bound
+	// of the pessimistic operator.
+	Components int
+}
+
+// VersionConstraints is a set of version constraints that all must match. It follows the syntax OpenTofu uses for
+// module and provider version constraints, e.g. ">= 1.2, < 2.0", "~> 1.4.0", "!= 1.3.1" or "1.2.3".
+//
+// Like in OpenTofu, prerelease versions only match if one of the constraints selects them exactly, for example with
+// "= 1.2.0-beta1".
+type VersionConstraints []VersionConstraint
+
+// ParseVersionConstraints parses a comma-separated list of version constraints. An empty string results in an empty
+// constraint set, which matches all versions except prereleases.
+func ParseVersionConstraints(constraints string) (VersionConstraints, error) {
+	if strings.TrimSpace(constraints) == "" {
+		return VersionConstraints{}, nil
+	}
+	parts := strings.Split(constraints, ",")
+	result := make(VersionConstraints, len(parts))
+	for i, part := range parts {
+		constraint, err := parseVersionConstraint(strings.TrimSpace(part))
+		if err != nil {
+			return nil, &InvalidVersionConstraintError{constraints, err.Error()}
+		}
+		result[i] = constraint
+	}
+	return result, nil
+}
+
+func parseVersionConstraint(text string) (VersionConstraint, error) {
+	operator := ConstraintOperatorEqual
+	for _, op := range constraintOperators {
+		if strings.HasPrefix(text, string(op)) {
+			operator = op
+			text = strings.TrimSpace(strings.TrimPrefix(text, string(op)))
+			break
+		}
+	}
+	if text == "" {
+		return VersionConstraint{}, &InvalidSemVerError{text, "missing version"}
+	}
+
+	core, suffix := text, ""
+	if i := strings.IndexAny(text, "-+"); i != -1 {
+		core, suffix = text[:i], text[i:]
+	}
+	components := strings.Count(strings.TrimPrefix(core, "v"), ".") + 1
+	if components > 3 {
+		return VersionConstraint{}, &InvalidSemVerError{text, "too many version components"}
+	}
+	if components < 3 && suffix != "" {
+		return VersionConstraint{}, &InvalidSemVerError{text, "prerelease and build metadata require a full version"}
+	}
+	padded := core + strings.Repeat(".0", 3-components) + suffix
+	version, err := ParseSemVer(padded)
+	if err != nil {
+		return VersionConstraint{}, err
+	}
+	return VersionConstraint{
+		Operator:   operator,
+		Version:    version,
+		Components: components,
+	}, nil
+}
+
+// String returns the constraint in its canonical form.
+func (c VersionConstraint) String() string {
+	version := c.Version.String()
+	if c.Components < 3 && !c.Version.IsPrerelease() && len(c.Version.Build) == 0 {
+		version = strings.Join(strings.Split(version, ".")[:c.Components], ".")
+	}
+	return string(c.Operator) + " " + version
+}
+
+// Check returns true if the version matches the constraint. It does not apply the prerelease rule of
+// VersionConstraints.
+func (c VersionConstraint) Check(version SemVer) bool {
+	result := version.Compare(c.Version)
+	switch c.Operator {
+	case ConstraintOperatorEqual:
+		return result == 0
+	case ConstraintOperatorNotEqual:
+		return result != 0
+	case ConstraintOperatorGreaterThan:
+		return result > 0
+	case ConstraintOperatorGreaterThanOrEqual:
+		return result >= 0
+	case ConstraintOperatorLessThan:
+		return result < 0
+	case ConstraintOperatorLessThanOrEqual:
+		return result <= 0
+	case ConstraintOperatorPessimistic:
+		if result < 0 {
+			return false
+		}
+		upper := SemVer{Major: c.Version.Major + 1}
+		if c.Components == 3 {
+			upper = SemVer{Major: c.Version.Major, Minor: c.Version.Minor + 1}
+		}
+		return version.Compare(upper) < 0
+	default:
+		return false
+	}
+}
+
+// String returns the constraints in their canonical form.
+func (v VersionConstraints) String() string {
+	parts := make([]string, len(v))
+	for i, constraint := range v {
+		parts[i] = constraint.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Check returns true if the version matches all constraints.
+func (v VersionConstraints) Check(version SemVer) bool {
+	if version.IsPrerelease() && !v.selectsExactly(version) {
+		return false
+	}
+	for _, constraint := range v {
+		if !constraint.Check(version) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v VersionConstraints) selectsExactly(version SemVer) bool {
+	for _, constraint := range v {
+		if constraint.Operator == ConstraintOperatorEqual && constraint.Version.Compare(version) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidVersionConstraintError indicates that a version constraint string could not be parsed.
+type InvalidVersionConstraintError struct {
+	Constraints string
+	Reason      string
+}
+
+func (i InvalidVersionConstraintError) Error() string {
+	return "Invalid version constraint " + i.Constraints + ": " + i.Reason
+}
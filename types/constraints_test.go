@@ -0,0 +1,102 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/opentofu/libregistry/types"
+)
+
+func TestVersionConstraints(t *testing.T) {
+	type testCase struct {
+		constraints string
+		matches     []string
+		nonMatches  []string
+	}
+
+	for name, tc := range map[string]testCase{
+		"empty": {
+			"",
+			[]string{"0.0.1", "1.2.3"},
+			[]string{"1.2.3-beta1"},
+		},
+		"exact": {
+			"1.2.3",
+			[]string{"1.2.3", "1.2.3+build.1"},
+			[]string{"1.2.4", "1.2.3-beta1"},
+		},
+		"range": {
+			">= 1.2, < 2.0",
+			[]string{"1.2.0", "1.9.9"},
+			[]string{"1.1.9", "2.0.0", "2.0.0-rc1"},
+		},
+		"pessimistic-patch": {
+			"~> 1.4.0",
+			[]string{"1.4.0", "1.4.9"},
+			[]string{"1.3.9", "1.5.0"},
+		},
+		"pessimistic-minor": {
+			"~> 1.4",
+			[]string{"1.4.0", "1.9.0"},
+			[]string{"1.3.9", "2.0.0"},
+		},
+		"not-equal": {
+			">= 1.3, != 1.3.1",
+			[]string{"1.3.0", "1.3.2"},
+			[]string{"1.3.1"},
+		},
+		"exact-prerelease": {
+			"= 1.2.0-beta1",
+			[]string{"1.2.0-beta1"},
+			[]string{"1.2.0-beta2", "1.2.0"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			constraints, err := types.ParseVersionConstraints(tc.constraints)
+			if err != nil {
+				t.Fatalf("Failed to parse constraints (%v)", err)
+			}
+			for _, version := range tc.matches {
+				if !constraints.Check(mustParseSemVer(t, version)) {
+					t.Fatalf("%s does not match %s", version, tc.constraints)
+				}
+			}
+			for _, version := range tc.nonMatches {
+				if constraints.Check(mustParseSemVer(t, version)) {
+					t.Fatalf("%s incorrectly matches %s", version, tc.constraints)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionConstraintsInvalid(t *testing.T) {
+	for _, constraints := range []string{">=", "1.2.3.4", "~> 1.2-beta", ">= 1.2,", "=> 1.2", "1.x"} {
+		t.Run(constraints, func(t *testing.T) {
+			if _, err := types.ParseVersionConstraints(constraints); err == nil {
+				t.Fatalf("Expected error was not returned.")
+			}
+		})
+	}
+}
+
+func TestVersionConstraintsString(t *testing.T) {
+	constraints, err := types.ParseVersionConstraints(">=1.2,<2.0.0,~>1.4")
+	if err != nil {
+		t.Fatalf("Failed to parse constraints (%v)", err)
+	}
+	if constraints.String() != ">= 1.2, < 2.0.0, ~> 1.4" {
+		t.Fatalf("Incorrect string representation: %s", constraints.String())
+	}
+}
+
+func mustParseSemVer(t *testing.T, version string) types.SemVer {
+	t.Helper()
+	parsed, err := types.ParseSemVer(version)
+	if err != nil {
+		t.Fatalf("Failed to parse %s (%v)", version, err)
+	}
+	return parsed
+}
@@ -0,0 +1,75 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"time"
+)
+
+// Blocklist holds the modules and namespaces that must not be added to the registry, for example because they were
+// removed on request of the author or for legal reasons.
+type Blocklist struct {
+	Modules    []BlockedModule    `json:"modules"`
+	Namespaces []BlockedNamespace `json:"namespaces"`
+}
+
+// BlocklistEntry describes why and when a module or namespace was blocked.
+type BlocklistEntry struct {
+	// Reason is the human-readable explanation for blocking the entry.
+	Reason string `json:"reason,omitempty"`
+	// Timestamp is the time the entry was blocked.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BlockedModule is a single module on the blocklist.
+type BlockedModule struct {
+	Addr Addr `json:"addr"`
+	BlocklistEntry
+}
+
+// BlockedNamespace is a namespace on the blocklist. All modules in the namespace are blocked.
+type BlockedNamespace struct {
+	Namespace string `json:"namespace"`
+	BlocklistEntry
+}
+
+// IsBlocked returns the blocklist entry matching the module. Namespace entries take precedence over module entries.
+// The second return value is false if the module is not blocked.
+func (b Blocklist) IsBlocked(moduleAddr Addr) (BlocklistEntry, bool) {
+	namespace := NormalizeNamespace(moduleAddr.Namespace)
+	for _, entry := range b.Namespaces {
+		if NormalizeNamespace(entry.Namespace) == namespace {
+			return entry.BlocklistEntry, true
+		}
+	}
+	for _, entry := range b.Modules {
+		if entry.Addr.Equals(moduleAddr) {
+			return entry.BlocklistEntry, true
+		}
+	}
+	return BlocklistEntry{}, false
+}
+
+// BlockModule adds the module to the blocklist. If the module is already blocked, the entry is replaced.
+func (b *Blocklist) BlockModule(moduleAddr Addr, entry BlocklistEntry) {
+	for i, blocked := range b.Modules {
+		if blocked.Addr.Equals(moduleAddr) {
+			b.Modules[i].BlocklistEntry = entry
+			return
+		}
+	}
+	b.Modules = append(b.Modules, BlockedModule{moduleAddr.Normalize(), entry})
+}
+
+// BlockNamespace adds the namespace to the blocklist. If the namespace is already blocked, the entry is replaced.
+func (b *Blocklist) BlockNamespace(namespace string, entry BlocklistEntry) {
+	namespace = NormalizeNamespace(namespace)
+	for i, blocked := range b.Namespaces {
+		if NormalizeNamespace(blocked.Namespace) == namespace {
+			b.Namespaces[i].BlocklistEntry = entry
+			return
+		}
+	}
+	b.Namespaces = append(b.Namespaces, BlockedNamespace{namespace, entry})
+}
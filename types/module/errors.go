@@ -3,6 +3,10 @@
 
 package module
 
+import (
+	"github.com/opentofu/libregistry/vcs"
+)
+
 type InvalidModuleAddrError struct {
 	Addr  Addr
 	Cause error
@@ -18,3 +22,22 @@ func (i InvalidModuleAddrError) Error() string {
 func (i InvalidModuleAddrError) Unwrap() error {
 	return i.Cause
 }
+
+// InvalidTagMappingError indicates that a TagMapping cannot be used.
+type InvalidTagMappingError struct {
+	TagMapping TagMapping
+	Reason     string
+}
+
+func (i InvalidTagMappingError) Error() string {
+	return "Invalid tag mapping: " + i.Reason
+}
+
+// TagNotMappedError indicates that a tag does not belong to the module according to its TagMapping.
+type TagNotMappedError struct {
+	Tag vcs.VersionNumber
+}
+
+func (t TagNotMappedError) Error() string {
+	return "Tag does not match the tag mapping: " + string(t.Tag)
+}
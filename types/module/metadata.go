@@ -3,13 +3,35 @@
 
 package module
 
+import (
+	"slices"
+)
+
 // Metadata represents all the metadata for a module. This includes the list of versions available for the module.
 // This structure represents the file in modules/o/opentofu/somemodule/platform.json.
 type Metadata struct {
+	// CustomRepository is an optional repository to fetch the module from instead of the repository named
+	// terraform-<target system>-<name> in the organization matching the namespace. It may start with a VCS host,
+	// e.g. gitlab.example.com/org/repo, to use a different VCS system.
+	CustomRepository string `json:"repository,omitempty"`
 	// Versions lists all available versions of a Namespace-Name-TargetSystem combination.
 	Versions VersionList `json:"versions"`
+	// Warnings for this module, such as license policy violations that were flagged, but not rejected.
+	Warnings []string `json:"warnings,omitempty"`
+	// TagMapping describes how the repository tags map to versions of this module. If it is empty, the tags are used
+	// as version numbers and the module is in the repository root.
+	TagMapping *TagMapping `json:"tag_mapping,omitempty"`
 }
 
 func (m Metadata) Equals(other Metadata) bool {
+	if m.CustomRepository != other.CustomRepository {
+		return false
+	}
+	if !slices.Equal(m.Warnings, other.Warnings) {
+		return false
+	}
+	if !m.TagMapping.Equals(other.TagMapping) {
+		return false
+	}
 	return m.Versions.Equals(other.Versions)
 }
@@ -0,0 +1,47 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func TestMetadataEquals(t *testing.T) {
+	status := &types.VersionStatus{Reason: "Broken release", Timestamp: time.Now()}
+	base := module.Metadata{
+		Versions: module.VersionList{{Version: "v1.0.0"}},
+	}
+	for name, other := range map[string]module.Metadata{
+		"yanked": {
+			Versions: module.VersionList{{Version: "v1.0.0", Yanked: status}},
+		},
+		"deprecated": {
+			Versions: module.VersionList{{Version: "v1.0.0", Deprecated: status}},
+		},
+		"commit": {
+			Versions: module.VersionList{{Version: "v1.0.0", Commit: "abc"}},
+		},
+		"warnings": {
+			Versions: base.Versions,
+			Warnings: []string{"License not allowed"},
+		},
+		"tag mapping": {
+			Versions:   base.Versions,
+			TagMapping: &module.TagMapping{Prefix: "vpc/"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if base.Equals(other) || other.Equals(base) {
+				t.Fatalf("The metadata is reported as equal despite a different %s.", name)
+			}
+			if !other.Equals(other) {
+				t.Fatalf("The metadata is not equal to itself.")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// TagMappingVersionGroup is the name of the capture group in TagMapping.Pattern that holds the version number.
+const TagMappingVersionGroup = "version"
+
+// TagMapping describes how the tags of a repository map to the versions of a module. This allows several modules to
+// be published from a single repository, for example with tags such as modules/vpc/v1.2.3 or vpc-v1.2.3.
+//
+// swagger:model ModuleTagMapping
+type TagMapping struct {
+	// Prefix is removed from the tags to obtain the version number. Tags without this prefix are ignored.
+	Prefix string `json:"prefix,omitempty"`
+	// Pattern is a regular expression that must match the whole tag. It must contain a capture group named "version"
+	// that holds the version number. Tags that do not match are ignored. Prefix and Pattern are mutually exclusive.
+	Pattern string `json:"pattern,omitempty"`
+	// Subdirectory is the directory in the repository that contains the module. It is empty if the module is in the
+	// repository root.
+	Subdirectory string `json:"subdirectory,omitempty"`
+}
+
+// Validate checks if the tag mapping is valid.
+func (t TagMapping) Validate() error {
+	if t.Prefix != "" && t.Pattern != "" {
+		return &InvalidTagMappingError{t, "prefix and pattern are mutually exclusive"}
+	}
+	if t.Pattern != "" {
+		if _, err := t.compilePattern(); err != nil {
+			return err
+		}
+	}
+	if t.Subdirectory != "" {
+		if path.IsAbs(t.Subdirectory) || path.Clean(t.Subdirectory) != t.Subdirectory || t.Subdirectory == "." ||
+			t.Subdirectory == ".." || strings.HasPrefix(t.Subdirectory, "../") {
+			return &InvalidTagMappingError{t, "the subdirectory must be a clean relative path inside the repository"}
+		}
+	}
+	return nil
+}
+
+// Equals returns true if both tag mappings are equal. Nil tag mappings are equal to each other.
+func (t *TagMapping) Equals(other *TagMapping) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return *t == *other
+}
+
+func (t TagMapping) compilePattern() (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + t.Pattern + ")$")
+	if err != nil {
+		return nil, &InvalidTagMappingError{t, fmt.Sprintf("invalid pattern (%v)", err)}
+	}
+	if re.SubexpIndex(TagMappingVersionGroup) == -1 {
+		return nil, &InvalidTagMappingError{t, "the pattern has no capture group named " + TagMappingVersionGroup}
+	}
+	return re, nil
+}
+
+// VersionFromTag returns the module version for a tag. It returns an error if the tag does not belong to the module
+// or does not contain a valid version number. If the tag mapping is nil, the tag is used as the version number.
+func (t *TagMapping) VersionFromTag(tag vcs.VersionNumber) (VersionNumber, error) {
+	if t == nil {
+		return VersionFromVCS(tag)
+	}
+	version := string(tag)
+	switch {
+	case t.Pattern != "":
+		re, err := t.compilePattern()
+		if err != nil {
+			return "", err
+		}
+		match := re.FindStringSubmatch(version)
+		if match == nil {
+			return "", &TagNotMappedError{tag}
+		}
+		version = match[re.SubexpIndex(TagMappingVersionGroup)]
+	case t.Prefix != "":
+		if !strings.HasPrefix(version, t.Prefix) {
+			return "", &TagNotMappedError{tag}
+		}
+		version = strings.TrimPrefix(version, t.Prefix)
+	}
+	ver := VersionNumber(version)
+	return ver, ver.Validate()
+}
+
+// GetSubdirectory returns the subdirectory of the module in the repository. It is safe to call on a nil mapping.
+func (t *TagMapping) GetSubdirectory() string {
+	if t == nil {
+		return ""
+	}
+	return t.Subdirectory
+}
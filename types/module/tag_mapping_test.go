@@ -0,0 +1,94 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module_test
+
+import (
+	"testing"
+
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+func TestTagMapping(t *testing.T) {
+	type testCase struct {
+		mapping         *module.TagMapping
+		tag             vcs.VersionNumber
+		expectError     bool
+		expectedVersion module.VersionNumber
+	}
+
+	for name, tc := range map[string]testCase{
+		"none": {
+			nil,
+			"v1.2.3",
+			false,
+			"v1.2.3",
+		},
+		"prefix": {
+			&module.TagMapping{Prefix: "vpc-"},
+			"vpc-v1.2.3",
+			false,
+			"v1.2.3",
+		},
+		"prefix-mismatch": {
+			&module.TagMapping{Prefix: "vpc-"},
+			"s3-v1.2.3",
+			true,
+			"",
+		},
+		"pattern": {
+			&module.TagMapping{Pattern: `modules/vpc/(?P<version>.+)`},
+			"modules/vpc/1.2.3",
+			false,
+			"1.2.3",
+		},
+		"pattern-partial": {
+			&module.TagMapping{Pattern: `vpc/(?P<version>.+)`},
+			"modules/vpc/1.2.3",
+			true,
+			"",
+		},
+		"invalid-version": {
+			&module.TagMapping{Prefix: "vpc-"},
+			"vpc-latest",
+			true,
+			"",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			version, err := tc.mapping.VersionFromTag(tc.tag)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("Expected error was not returned.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error returned: %v", err)
+			}
+			if version != tc.expectedVersion {
+				t.Fatalf("Incorrect version: %s (expected: %s)", version, tc.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestTagMappingValidate(t *testing.T) {
+	for name, mapping := range map[string]module.TagMapping{
+		"both":             {Prefix: "vpc-", Pattern: `(?P<version>.+)`},
+		"no-version-group": {Pattern: `vpc-(.+)`},
+		"invalid-pattern":  {Pattern: `(?P<version>`},
+		"parent-directory": {Subdirectory: "../vpc"},
+		"absolute":         {Subdirectory: "/modules/vpc"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := mapping.Validate(); err == nil {
+				t.Fatalf("Expected error was not returned.")
+			}
+		})
+	}
+	if err := (module.TagMapping{Prefix: "vpc-", Subdirectory: "modules/vpc"}).Validate(); err != nil {
+		t.Fatalf("Unexpected error returned: %v", err)
+	}
+}
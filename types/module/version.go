@@ -3,20 +3,72 @@
 
 package module
 
+import (
+	"slices"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/vcs"
+)
+
 // Version represents a single version of a module.
 type Version struct {
 	// Version number of the provider. Correlates to a tag in the module repository.
 	Version VersionNumber `json:"version"`
+	// Tag is the repository tag of this version if it differs from the version number, e.g. modules/vpc/v1.2.3 when
+	// a tag mapping is used.
+	Tag vcs.VersionNumber `json:"tag,omitempty"`
+	// Licenses lists the SPDX IDs of the licenses detected in this version. This is empty if license detection was
+	// not performed or no license file was found.
+	Licenses []string `json:"licenses,omitempty"`
+	// Published is the time the version was tagged, if known.
+	Published *time.Time `json:"published,omitempty"`
+	// Commit is the commit SHA the version tag resolved to when the version was first seen, if known.
+	Commit string `json:"commit,omitempty"`
+	// SourceURL is the canonical source address to download this version from, e.g.
+	// git::https://github.com/opentofu/terraform-aws-example?ref=v1.0.0. This is empty if the VCS system does not
+	// provide web access.
+	SourceURL string `json:"source_url,omitempty"`
+	// Deprecated is set if the version should no longer be used, but is still available.
+	Deprecated *types.VersionStatus `json:"deprecated,omitempty"`
+	// Yanked is set if the version has been withdrawn and should not be offered for installation. Yanked versions
+	// stay yanked when the tags are synchronized again.
+	Yanked *types.VersionStatus `json:"yanked,omitempty"`
 }
 
 func (v Version) Normalize() Version {
-	return Version{
-		Version: v.Version.Normalize(),
-	}
+	v.Version = v.Version.Normalize()
+	return v
 }
 
+// Equals compares all fields of the versions, including the yanked and deprecated status. Version numbers are
+// compared in their normalized form.
 func (v Version) Equals(other Version) bool {
-	return v.Version.Normalize() == other.Version.Normalize()
+	if v.Version.Normalize() != other.Version.Normalize() {
+		return false
+	}
+	if v.Tag != other.Tag || v.Commit != other.Commit || v.SourceURL != other.SourceURL {
+		return false
+	}
+	if !slices.Equal(v.Licenses, other.Licenses) {
+		return false
+	}
+	if v.Published == nil || other.Published == nil {
+		if v.Published != other.Published {
+			return false
+		}
+	} else if !v.Published.Equal(*other.Published) {
+		return false
+	}
+	return v.Deprecated.Equals(other.Deprecated) && v.Yanked.Equals(other.Yanked)
+}
+
+// ToVCSVersion returns the repository tag of the version.
+func (v Version) ToVCSVersion() vcs.VersionNumber {
+	if v.Tag != "" {
+		return v.Tag
+	}
+	return v.Version.ToVCSVersion()
 }
 
 func (v Version) Compare(other Version) int {
@@ -0,0 +1,68 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module
+
+import (
+	"encoding/json"
+)
+
+// VersionDetails holds the results of analyzing the contents of a single module version. This structure represents
+// the file in module-details/o/opentofu/somemodule/platform/v1.0.0.json.
+type VersionDetails struct {
+	// Root describes the module in the root directory of the repository.
+	Root Details `json:"root"`
+	// Submodules lists the modules found in the modules/ directory.
+	Submodules []Details `json:"submodules,omitempty"`
+	// Examples lists the example configurations found in the examples/ directory.
+	Examples []Details `json:"examples,omitempty"`
+}
+
+// Details describes the interface of a single module directory.
+type Details struct {
+	// Path is the directory of the module relative to the repository root. The root module has the path ".".
+	Path              string             `json:"path"`
+	Variables         []Variable         `json:"variables,omitempty"`
+	Outputs           []Output           `json:"outputs,omitempty"`
+	RequiredProviders []RequiredProvider `json:"required_providers,omitempty"`
+	Resources         []Resource         `json:"resources,omitempty"`
+	// Error describes why a submodule or example could not be analyzed. The other fields except for Path are empty
+	// if this is set.
+	Error string `json:"error,omitempty"`
+}
+
+// Variable describes an input variable of a module.
+type Variable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Default holds the JSON representation of the default value. This is empty if the variable has no default.
+	Default   json.RawMessage `json:"default,omitempty"`
+	Required  bool            `json:"required"`
+	Sensitive bool            `json:"sensitive,omitempty"`
+}
+
+// Output describes an output value of a module.
+type Output struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+}
+
+// RequiredProvider describes a provider requirement of a module.
+type RequiredProvider struct {
+	// Name is the local name of the provider within the module.
+	Name string `json:"name"`
+	// Source is the provider source address, if specified.
+	Source string `json:"source,omitempty"`
+	// VersionConstraints lists all version constraints for this provider found in the module.
+	VersionConstraints []string `json:"version_constraints,omitempty"`
+}
+
+// Resource describes a resource or data source used in a module.
+type Resource struct {
+	// Mode is "managed" for resources and "data" for data sources.
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
@@ -5,18 +5,29 @@ package module
 
 import (
 	"slices"
+
+	"github.com/opentofu/libregistry/types"
 )
 
 // VersionList is a slice of versions.
 type VersionList []Version
 
-// Merge merges the current list with another list and returns the new merged list.
+// Merge merges the current list with another list and returns the new merged list. Versions in the other list take
+// precedence, but the deprecated and yanked states of the current list are kept if the other list does not set them.
 func (v VersionList) Merge(other VersionList) VersionList {
 	verSet := map[VersionNumber]Version{}
 	for _, ver := range v {
 		verSet[ver.Version.Normalize()] = ver
 	}
 	for _, ver := range other {
+		if existing, ok := verSet[ver.Version.Normalize()]; ok {
+			if ver.Deprecated == nil {
+				ver.Deprecated = existing.Deprecated
+			}
+			if ver.Yanked == nil {
+				ver.Yanked = existing.Yanked
+			}
+		}
 		verSet[ver.Version.Normalize()] = ver
 	}
 	newVersions := make(VersionList, len(verSet))
@@ -37,6 +48,81 @@ func (v VersionList) Sort() {
 	slices.SortFunc(v, semverSortFunc)
 }
 
+// Select returns the newest version that matches the constraints. Yanked versions and versions that are not valid
+// semantic versions are never selected. It returns false if no version matches.
+func (v VersionList) Select(constraints VersionConstraints) (Version, bool) {
+	return v.newest(func(version Version, _ types.SemVer) bool {
+		return constraints.Check(version.Version)
+	})
+}
+
+// Latest returns the newest version that is not yanked. Prerelease versions are only considered if includePrerelease
+// is true. It returns false if there is no such version.
+func (v VersionList) Latest(includePrerelease bool) (Version, bool) {
+	return v.newest(func(_ Version, parsed types.SemVer) bool {
+		return includePrerelease || !parsed.IsPrerelease()
+	})
+}
+
+// NewestPerMajor returns the newest stable, not yanked version for each major version, newest first.
+func (v VersionList) NewestPerMajor() VersionList {
+	return v.newestPer(func(parsed types.SemVer) [2]int {
+		return [2]int{parsed.Major, 0}
+	})
+}
+
+// NewestPerMinor returns the newest stable, not yanked version for each major and minor version, newest first.
+func (v VersionList) NewestPerMinor() VersionList {
+	return v.newestPer(func(parsed types.SemVer) [2]int {
+		return [2]int{parsed.Major, parsed.Minor}
+	})
+}
+
+// newest returns the newest not yanked version the filter accepts.
+func (v VersionList) newest(filter func(version Version, parsed types.SemVer) bool) (Version, bool) {
+	var result Version
+	var resultParsed types.SemVer
+	found := false
+	for _, version := range v {
+		if version.Yanked != nil {
+			continue
+		}
+		parsed, err := version.Version.ParseSemVer()
+		if err != nil || !filter(version, parsed) {
+			continue
+		}
+		if !found || parsed.Compare(resultParsed) > 0 {
+			result = version
+			resultParsed = parsed
+			found = true
+		}
+	}
+	return result, found
+}
+
+// newestPer groups the stable, not yanked versions by the key and returns the newest version of each group, newest
+// first.
+func (v VersionList) newestPer(key func(parsed types.SemVer) [2]int) VersionList {
+	groups := map[[2]int]VersionList{}
+	for _, version := range v {
+		if version.Yanked != nil {
+			continue
+		}
+		parsed, err := version.Version.ParseSemVer()
+		if err != nil || parsed.IsPrerelease() {
+			continue
+		}
+		groups[key(parsed)] = append(groups[key(parsed)], version)
+	}
+	result := make(VersionList, 0, len(groups))
+	for _, group := range groups {
+		newest, _ := group.Latest(false)
+		result = append(result, newest)
+	}
+	result.Sort()
+	return result
+}
+
 func (v VersionList) Equals(other VersionList) bool {
 	if len(v) != len(other) {
 		return false
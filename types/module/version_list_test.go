@@ -0,0 +1,84 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package module_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/types"
+	"github.com/opentofu/libregistry/types/module"
+)
+
+func TestVersionListMergeKeepsYanked(t *testing.T) {
+	yanked := &types.VersionStatus{Reason: "Broken release", Timestamp: time.Now()}
+	existing := module.VersionList{
+		{Version: "v1.0.0", Yanked: yanked},
+	}
+	merged := existing.Merge(module.VersionList{
+		{Version: "v1.1.0"},
+		{Version: "v1.0.0", Commit: "abc"},
+	})
+	if len(merged) != 2 {
+		t.Fatalf("Incorrect number of versions: %d", len(merged))
+	}
+	if merged[1].Commit != "abc" {
+		t.Fatalf("The new version data was not merged: %v", merged[1])
+	}
+	if !merged[1].Yanked.Equals(yanked) {
+		t.Fatalf("The yanked state was not kept: %v", merged[1].Yanked)
+	}
+	if merged[0].Yanked != nil {
+		t.Fatalf("The yanked state was set on the wrong version.")
+	}
+}
+
+func TestVersionListSelect(t *testing.T) {
+	versions := module.VersionList{
+		{Version: "v1.3.0"},
+		{Version: "v2.1.0"},
+		{Version: "v1.4.2", Yanked: &types.VersionStatus{Reason: "Broken release"}},
+		{Version: "v1.4.1"},
+		{Version: "v2.2.0-beta1"},
+		{Version: "v2.0.0"},
+	}
+
+	constraints, err := module.ParseVersionConstraints("~> 1.3")
+	if err != nil {
+		t.Fatalf("Failed to parse constraints (%v)", err)
+	}
+	selected, ok := versions.Select(constraints)
+	if !ok {
+		t.Fatalf("No version was selected.")
+	}
+	if selected.Version != "v1.4.1" {
+		t.Fatalf("Incorrect version selected: %s", selected.Version)
+	}
+
+	constraints, err = module.ParseVersionConstraints(">= 3.0")
+	if err != nil {
+		t.Fatalf("Failed to parse constraints (%v)", err)
+	}
+	if _, ok := versions.Select(constraints); ok {
+		t.Fatalf("A version was selected even though none matches.")
+	}
+
+	latest, ok := versions.Latest(false)
+	if !ok || latest.Version != "v2.1.0" {
+		t.Fatalf("Incorrect latest version: %s", latest.Version)
+	}
+	latest, ok = versions.Latest(true)
+	if !ok || latest.Version != "v2.2.0-beta1" {
+		t.Fatalf("Incorrect latest version including prereleases: %s", latest.Version)
+	}
+
+	perMajor := versions.NewestPerMajor()
+	if len(perMajor) != 2 || perMajor[0].Version != "v2.1.0" || perMajor[1].Version != "v1.4.1" {
+		t.Fatalf("Incorrect newest versions per major version: %v", perMajor)
+	}
+	perMinor := versions.NewestPerMinor()
+	if len(perMinor) != 4 || perMinor[3].Version != "v1.3.0" {
+		t.Fatalf("Incorrect newest versions per minor version: %v", perMinor)
+	}
+}
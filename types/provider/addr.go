@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/opentofu/libregistry/vcs"
+	regaddr "github.com/opentofu/registry-address"
 )
 
 // Addr represents a full provider address (NAMESPACE/NAME). It currently translates to
@@ -18,6 +19,18 @@ type Addr struct {
 	Name      string `json:"-"`
 }
 
+func (a Addr) Validate() error {
+	for _, part := range []string{a.Namespace, a.Name} {
+		if _, err := regaddr.ParseProviderPart(part); err != nil {
+			return &InvalidProviderAddrError{
+				a,
+				err,
+			}
+		}
+	}
+	return nil
+}
+
 func (a Addr) MarshalJSON() ([]byte, error) {
 	// Note: this intentionally doesn't have a pointer receiver! Don't add one!
 	normalized := a.Normalize()
@@ -30,3 +30,20 @@ func TestAddrJSON(t *testing.T) {
 		t.Fatalf("Provider addresses are not equal.")
 	}
 }
+
+func TestAddrValidate(t *testing.T) {
+	if err := (provider.Addr{Namespace: "opentofu", Name: "test"}).Validate(); err != nil {
+		t.Fatalf("Failed to validate a valid provider address (%v)", err)
+	}
+	for _, providerAddr := range []provider.Addr{
+		{},
+		{Namespace: "opentofu"},
+		{Name: "test"},
+		{Namespace: "open tofu", Name: "test"},
+		{Namespace: "opentofu", Name: "test/other"},
+	} {
+		if err := providerAddr.Validate(); err == nil {
+			t.Fatalf("Validating an invalid provider address did not fail: %#v", providerAddr)
+		}
+	}
+}
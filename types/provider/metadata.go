@@ -14,6 +14,14 @@ func (m Metadata) Equals(other Metadata) bool {
 	if m.CustomRepository != other.CustomRepository {
 		return false
 	}
+	if len(m.Warnings) != len(other.Warnings) {
+		return false
+	}
+	for i, warning := range m.Warnings {
+		if warning != other.Warnings[i] {
+			return false
+		}
+	}
 	if len(m.Versions) != len(other.Versions) {
 		return false
 	}
@@ -3,6 +3,10 @@
 
 package provider
 
+import (
+	"github.com/opentofu/libregistry/types"
+)
+
 // Version contains information about a specific provider version.
 type Version struct {
 	Version             VersionNumber `json:"version"`               // The version number of the provider.
@@ -10,6 +14,11 @@ type Version struct {
 	SHASumsURL          string        `json:"shasums_url"`           // The URL to the SHA checksums file.
 	SHASumsSignatureURL string        `json:"shasums_signature_url"` // The URL to the GPG signature of the SHA checksums file.
 	Targets             []Target      `json:"targets"`               // A list of target platforms for which this provider version is available.
+	Licenses            []string      `json:"licenses,omitempty"`    // The SPDX IDs of the licenses detected in this version.
+	// Deprecated is set if the version should no longer be used, but is still available.
+	Deprecated *types.VersionStatus `json:"deprecated,omitempty"`
+	// Yanked is set if the version has been withdrawn and should not be offered for installation.
+	Yanked *types.VersionStatus `json:"yanked,omitempty"`
 }
 
 func (v Version) Normalize() Version {
@@ -19,6 +28,9 @@ func (v Version) Normalize() Version {
 		SHASumsURL:          v.SHASumsURL,
 		SHASumsSignatureURL: v.SHASumsSignatureURL,
 		Targets:             v.Targets,
+		Licenses:            v.Licenses,
+		Deprecated:          v.Deprecated,
+		Yanked:              v.Yanked,
 	}
 }
 
@@ -48,7 +60,15 @@ func (v Version) Equals(other Version) bool {
 			return false
 		}
 	}
-	return true
+	if len(v.Licenses) != len(other.Licenses) {
+		return false
+	}
+	for i, license := range v.Licenses {
+		if license != other.Licenses[i] {
+			return false
+		}
+	}
+	return v.Deprecated.Equals(other.Deprecated) && v.Yanked.Equals(other.Yanked)
 }
 
 func (v Version) Compare(other Version) int {
@@ -0,0 +1,32 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"github.com/opentofu/libregistry/types"
+)
+
+// VersionConstraints is a parsed set of provider version constraints in the OpenTofu syntax, e.g. ">= 1.2, < 2.0".
+type VersionConstraints struct {
+	types.VersionConstraints
+}
+
+// ParseVersionConstraints parses a comma-separated list of version constraints. See types.VersionConstraints for
+// the supported syntax.
+func ParseVersionConstraints(constraints string) (VersionConstraints, error) {
+	parsed, err := types.ParseVersionConstraints(constraints)
+	if err != nil {
+		return VersionConstraints{}, err
+	}
+	return VersionConstraints{parsed}, nil
+}
+
+// Check returns true if the version number is valid and matches all constraints.
+func (c VersionConstraints) Check(version VersionNumber) bool {
+	parsed, err := version.ParseSemVer()
+	if err != nil {
+		return false
+	}
+	return c.VersionConstraints.Check(parsed)
+}
@@ -5,31 +5,13 @@ package provider
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 
+	"github.com/opentofu/libregistry/types"
 	"github.com/opentofu/libregistry/vcs"
 	"golang.org/x/mod/semver"
 )
 
-const majorName = "major"
-const minorName = "minor"
-const patchName = "patch"
-const stabilityName = "stability"
-const stabilityNumberName = "stabilityNumber"
-
-var versionRe = regexp.MustCompile(`^v(?P<` + majorName + `>[0-9]+)\.(?P<` + minorName + `>[0-9]+)\.(?P<` + patchName + `>[0-9]+)(|-(?P<` + stabilityName + `>[a-zA-Z.-]+)(?P<` + stabilityNumberName + `>[0-9]+))$`)
-var versionReNames = map[string]int{}
-
-func init() {
-	for index, name := range versionRe.SubexpNames() {
-		if name != "" {
-			versionReNames[name] = index
-		}
-	}
-}
-
 const maxVersionLength = 255
 
 // VersionNumber describes the semver version number.
@@ -41,8 +23,16 @@ func (v VersionNumber) Normalize() VersionNumber {
 	return VersionNumber("v" + strings.TrimPrefix(string(v), "v"))
 }
 
+// Compare returns the precedence of the version number compared to the other version number according to the
+// Semantic Versioning 2.0.0 specification. Build metadata is ignored.
 func (v VersionNumber) Compare(other VersionNumber) int {
-	return semver.Compare(string(v.Normalize()), string(other.Normalize()))
+	parsedV, errV := v.ParseSemVer()
+	parsedOther, errOther := other.ParseSemVer()
+	if errV != nil || errOther != nil {
+		// Fall back to the lenient comparison for invalid versions so sorting stays stable.
+		return semver.Compare(string(v.Normalize()), string(other.Normalize()))
+	}
+	return parsedV.Compare(parsedOther)
 }
 
 func (v VersionNumber) Validate() error {
@@ -50,7 +40,7 @@ func (v VersionNumber) Validate() error {
 	if len(normalizedV) > maxVersionLength {
 		return &InvalidVersionNumber{v}
 	}
-	if !versionRe.MatchString(string(normalizedV)) {
+	if _, err := types.ParseSemVer(string(normalizedV)); err != nil {
 		return &InvalidVersionNumber{v}
 	}
 	return nil
@@ -62,25 +52,23 @@ func (v VersionNumber) ToVCSVersion() vcs.VersionNumber {
 	return vcs.VersionNumber(v)
 }
 
-func (v VersionNumber) Parse() (major int, minor int, patch int, stability string, stabilityNumber int, err error) {
-	versionReNames := versionReNames
-	submatches := versionRe.FindStringSubmatch(string(v.Normalize()))
-	if len(submatches) == 0 {
-		return 0, 0, 0, "", 0, fmt.Errorf("failed to parse version (must match %s)", versionRe.String())
+// ParseSemVer parses the version number according to the Semantic Versioning 2.0.0 specification.
+func (v VersionNumber) ParseSemVer() (types.SemVer, error) {
+	normalizedV := v.Normalize()
+	if len(normalizedV) > maxVersionLength {
+		return types.SemVer{}, &InvalidVersionNumber{v}
 	}
-	numbers := map[string]int{}
-	for _, matchName := range []string{majorName, minorName, patchName, stabilityNumberName} {
-		value := submatches[versionReNames[matchName]]
-		if matchName == stabilityNumberName && value == "" {
-			numbers[matchName] = 0
-			continue
-		}
-		numbers[matchName], err = strconv.Atoi(value)
-		if err != nil {
-			return 0, 0, 0, "", 0, fmt.Errorf("failed to parse version (%s is invalid: %w)", matchName, err)
-		}
+	return types.ParseSemVer(string(normalizedV))
+}
+
+// Parse returns the version number in the legacy tuple form. New code should use ParseSemVer instead.
+func (v VersionNumber) Parse() (major int, minor int, patch int, stability string, stabilityNumber int, err error) {
+	parsed, err := v.ParseSemVer()
+	if err != nil {
+		return 0, 0, 0, "", 0, fmt.Errorf("failed to parse version (%w)", err)
 	}
-	return numbers[majorName], numbers[minorName], numbers[patchName], submatches[versionReNames[stabilityName]], numbers[stabilityNumberName], nil
+	stability, stabilityNumber = parsed.Stability()
+	return parsed.Major, parsed.Minor, parsed.Patch, stability, stabilityNumber, nil
 }
 
 type InvalidVersionNumber struct {
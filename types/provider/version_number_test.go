@@ -44,6 +44,26 @@ func TestVersionNumber(t *testing.T) {
 		},
 		"stability": {
 			"1.2.3-alpha",
+			false,
+			1, 2, 3, "alpha", 0,
+		},
+		"dotted-prerelease": {
+			"1.2.3-rc.1",
+			false,
+			1, 2, 3, "rc.", 1,
+		},
+		"build-metadata": {
+			"1.2.3-rc.1+build.5",
+			false,
+			1, 2, 3, "rc.", 1,
+		},
+		"leading-zero": {
+			"1.02.3",
+			true,
+			0, 0, 0, "", 0,
+		},
+		"empty-prerelease": {
+			"1.2.3-",
 			true,
 			0, 0, 0, "", 0,
 		},
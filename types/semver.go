@@ -0,0 +1,215 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is the structured form of a version number according to the Semantic Versioning 2.0.0 specification. See
+// https://semver.org/spec/v2.0.0.html for details.
+type SemVer struct {
+	Major int
+	Minor int
+	Patch int
+	// Prerelease contains the dot-separated prerelease identifiers, e.g. ["rc", "1"] for 1.0.0-rc.1. It is empty for
+	// normal versions.
+	Prerelease []string
+	// Build contains the dot-separated build metadata identifiers, e.g. ["build", "5"] for 1.0.0+build.5. Build
+	// metadata is ignored when determining precedence.
+	Build []string
+}
+
+// ParseSemVer parses a version number according to the Semantic Versioning 2.0.0 specification. An optional "v"
+// prefix is accepted.
+func ParseSemVer(version string) (SemVer, error) {
+	text := strings.TrimPrefix(version, "v")
+	result := SemVer{}
+
+	if before, build, ok := strings.Cut(text, "+"); ok {
+		identifiers, err := parseSemVerIdentifiers(build, false)
+		if err != nil {
+			return SemVer{}, &InvalidSemVerError{version, "invalid build metadata: " + err.Error()}
+		}
+		result.Build = identifiers
+		text = before
+	}
+	if before, prerelease, ok := strings.Cut(text, "-"); ok {
+		identifiers, err := parseSemVerIdentifiers(prerelease, true)
+		if err != nil {
+			return SemVer{}, &InvalidSemVerError{version, "invalid prerelease: " + err.Error()}
+		}
+		result.Prerelease = identifiers
+		text = before
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) != 3 {
+		return SemVer{}, &InvalidSemVerError{version, "must consist of major, minor and patch version"}
+	}
+	for i, target := range []*int{&result.Major, &result.Minor, &result.Patch} {
+		number, err := parseSemVerNumber(parts[i])
+		if err != nil {
+			return SemVer{}, &InvalidSemVerError{version, err.Error()}
+		}
+		*target = number
+	}
+	return result, nil
+}
+
+// parseSemVerIdentifiers parses a dot-separated list of identifiers. Numeric prerelease identifiers must not have
+// leading zeros, while build metadata identifiers may.
+func parseSemVerIdentifiers(text string, prerelease bool) ([]string, error) {
+	identifiers := strings.Split(text, ".")
+	for _, identifier := range identifiers {
+		if identifier == "" {
+			return nil, fmt.Errorf("empty identifier")
+		}
+		numeric := true
+		for _, c := range identifier {
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+				numeric = false
+			default:
+				return nil, fmt.Errorf("invalid character in identifier %q", identifier)
+			}
+		}
+		if prerelease && numeric && len(identifier) > 1 && identifier[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", identifier)
+		}
+	}
+	return identifiers, nil
+}
+
+func parseSemVerNumber(text string) (int, error) {
+	if text == "" {
+		return 0, fmt.Errorf("empty version component")
+	}
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("version component %q is not a number", text)
+		}
+	}
+	if len(text) > 1 && text[0] == '0' {
+		return 0, fmt.Errorf("version component %q has a leading zero", text)
+	}
+	number, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("version component %q is out of range", text)
+	}
+	return number, nil
+}
+
+// IsPrerelease returns true if the version has prerelease identifiers.
+func (s SemVer) IsPrerelease() bool {
+	return len(s.Prerelease) > 0
+}
+
+// String returns the canonical version string without a "v" prefix.
+func (s SemVer) String() string {
+	result := fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch)
+	if len(s.Prerelease) > 0 {
+		result += "-" + strings.Join(s.Prerelease, ".")
+	}
+	if len(s.Build) > 0 {
+		result += "+" + strings.Join(s.Build, ".")
+	}
+	return result
+}
+
+// Compare returns -1 if s has a lower precedence than other, 1 if it has a higher precedence and 0 if both have the
+// same precedence. Build metadata does not influence precedence.
+func (s SemVer) Compare(other SemVer) int {
+	for _, pair := range [][2]int{{s.Major, other.Major}, {s.Minor, other.Minor}, {s.Patch, other.Patch}} {
+		if result := compareInt(pair[0], pair[1]); result != 0 {
+			return result
+		}
+	}
+	// A normal version has a higher precedence than any prerelease of the same version.
+	switch {
+	case len(s.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(s.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(s.Prerelease) && i < len(other.Prerelease); i++ {
+		if result := comparePrereleaseIdentifier(s.Prerelease[i], other.Prerelease[i]); result != 0 {
+			return result
+		}
+	}
+	return compareInt(len(s.Prerelease), len(other.Prerelease))
+}
+
+// comparePrereleaseIdentifier compares numeric identifiers numerically and alphanumeric identifiers lexically in
+// ASCII order. Numeric identifiers have a lower precedence than alphanumeric identifiers.
+func comparePrereleaseIdentifier(a string, b string) int {
+	aNumeric := isNumericIdentifier(a)
+	bNumeric := isNumericIdentifier(b)
+	switch {
+	case aNumeric && bNumeric:
+		// Numeric identifiers have no leading zeros, so the longer one is the larger.
+		if result := compareInt(len(a), len(b)); result != 0 {
+			return result
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func isNumericIdentifier(identifier string) bool {
+	for _, c := range identifier {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Stability returns the prerelease in the legacy stability format used by VersionNumber.Parse: the prerelease
+// without its trailing digits and the trailing digits as a number. For example, 1.0.0-rc.1 has a stability of "rc."
+// and a stability number of 1.
+func (s SemVer) Stability() (string, int) {
+	prerelease := strings.Join(s.Prerelease, ".")
+	stability := strings.TrimRight(prerelease, "0123456789")
+	if stability == prerelease {
+		return stability, 0
+	}
+	stabilityNumber, err := strconv.Atoi(prerelease[len(stability):])
+	if err != nil {
+		return prerelease, 0
+	}
+	return stability, stabilityNumber
+}
+
+// InvalidSemVerError indicates that a version number does not conform to the Semantic Versioning 2.0.0
+// specification.
+type InvalidSemVerError struct {
+	Version string
+	Reason  string
+}
+
+func (i InvalidSemVerError) Error() string {
+	return "Invalid semantic version " + i.Version + ": " + i.Reason
+}
@@ -0,0 +1,92 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/libregistry/types"
+)
+
+func TestParseSemVer(t *testing.T) {
+	parsed, err := types.ParseSemVer("v1.2.3-rc.1+build.005")
+	if err != nil {
+		t.Fatalf("Failed to parse version (%v)", err)
+	}
+	if parsed.Major != 1 || parsed.Minor != 2 || parsed.Patch != 3 {
+		t.Fatalf("Incorrect version core: %d.%d.%d", parsed.Major, parsed.Minor, parsed.Patch)
+	}
+	if strings.Join(parsed.Prerelease, ".") != "rc.1" {
+		t.Fatalf("Incorrect prerelease: %v", parsed.Prerelease)
+	}
+	if strings.Join(parsed.Build, ".") != "build.005" {
+		t.Fatalf("Incorrect build metadata: %v", parsed.Build)
+	}
+	if parsed.String() != "1.2.3-rc.1+build.005" {
+		t.Fatalf("Incorrect string representation: %s", parsed.String())
+	}
+
+	for _, version := range []string{
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.2.3-01",
+		"1.2.3-rc..1",
+		"1.2.3+",
+		"1.2.3-rc_1",
+		"1.2.3+build+5",
+	} {
+		t.Run(version, func(t *testing.T) {
+			if _, err := types.ParseSemVer(version); err == nil {
+				t.Fatalf("Expected error was not returned.")
+			}
+		})
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	// This list is in ascending order of precedence, taken from the SemVer 2.0.0 specification.
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := types.ParseSemVer(ordered[i])
+			if err != nil {
+				t.Fatalf("Failed to parse %s (%v)", ordered[i], err)
+			}
+			b, err := types.ParseSemVer(ordered[j])
+			if err != nil {
+				t.Fatalf("Failed to parse %s (%v)", ordered[j], err)
+			}
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			if result := a.Compare(b); result != expected {
+				t.Fatalf("Incorrect comparison of %s and %s: %d (expected: %d)", ordered[i], ordered[j], result, expected)
+			}
+		}
+	}
+
+	a, _ := types.ParseSemVer("1.0.0+build.1")
+	b, _ := types.ParseSemVer("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Fatalf("Build metadata must not influence precedence.")
+	}
+}
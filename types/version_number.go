@@ -4,6 +4,9 @@
 package types
 
 type VersionNumber interface {
-	// Parse the version number into major, minor, patch, stability, and stability number.
+	// Parse the version number into major, minor, patch, stability, and stability number. The stability is the
+	// prerelease without its trailing digits, see SemVer.Stability. New code should use ParseSemVer instead.
 	Parse() (major int, minor int, patch int, stability string, stabilityNumber int, err error)
+	// ParseSemVer parses the version number into its structured Semantic Versioning 2.0.0 form.
+	ParseSemVer() (SemVer, error)
 }
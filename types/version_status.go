@@ -0,0 +1,24 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package types
+
+import (
+	"time"
+)
+
+// VersionStatus records that a version was deprecated or yanked.
+type VersionStatus struct {
+	// Reason is the human-readable explanation shown to users.
+	Reason string `json:"reason,omitempty"`
+	// Timestamp is the time the status was set.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Equals returns true if both statuses are equal. Nil statuses are equal to each other.
+func (v *VersionStatus) Equals(other *VersionStatus) bool {
+	if v == nil || other == nil {
+		return v == other
+	}
+	return v.Reason == other.Reason && v.Timestamp.Equal(other.Timestamp)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentofu/libregistry/types/module"
+	"golang.org/x/sync/errgroup"
+)
+
+// UpdateAllOptions configures a bulk update.
+type UpdateAllOptions struct {
+	// Workers is the number of updates running concurrently. Defaults to 1.
+	Workers int
+	// RateLimit is the minimum time between the start of two updates across all workers. Use this to stay within
+	// the rate limits of the VCS system. Defaults to 0, meaning no limit.
+	RateLimit time.Duration
+	// Progress is called after each update has finished. Calls are serialized, so the callback does not need to be
+	// safe for concurrent use. Defaults to no callback.
+	Progress func(progress UpdateProgress)
+}
+
+// UpdateProgress describes the progress of a bulk update after a single module was processed.
+type UpdateProgress struct {
+	// Completed is the number of modules processed so far, including this one.
+	Completed int
+	// Total is the number of modules to process.
+	Total int
+	// Report is the outcome of the module update that just finished.
+	Report ModuleUpdateReport
+}
+
+// ModuleUpdateReport describes the outcome of updating a single module during a bulk update.
+type ModuleUpdateReport struct {
+	Module module.Addr
+	// Result holds the changes made by the update. It is empty if the update failed.
+	Result ModuleUpdateResult
+	// Error is the reason the update failed, or nil if it succeeded.
+	Error error
+}
+
+// UpdateAllReport is the aggregated outcome of a bulk update. Each list is sorted by module address.
+type UpdateAllReport struct {
+	// Updated lists the modules whose versions changed.
+	Updated []ModuleUpdateReport
+	// Unchanged lists the modules that were updated successfully, but whose versions did not change.
+	Unchanged []ModuleUpdateReport
+	// Failed lists the modules that could not be updated along with the reason.
+	Failed []ModuleUpdateReport
+}
+
+func (o *UpdateAllOptions) applyDefaults() {
+	if o.Workers < 1 {
+		o.Workers = 1
+	}
+}
+
+func (m api) UpdateAllModules(ctx context.Context, opts UpdateAllOptions) (UpdateAllReport, error) {
+	opts.applyDefaults()
+
+	moduleAddrs, err := m.dataAPI.ListModules(ctx)
+	if err != nil {
+		return UpdateAllReport{}, fmt.Errorf("failed to list modules (%w)", err)
+	}
+
+	var limiter <-chan time.Time
+	if opts.RateLimit > 0 {
+		ticker := time.NewTicker(opts.RateLimit)
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	report := UpdateAllReport{}
+	lock := &sync.Mutex{}
+	completed := 0
+	record := func(moduleReport ModuleUpdateReport) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case moduleReport.Error != nil:
+			report.Failed = append(report.Failed, moduleReport)
+		case moduleReport.Result.Changed():
+			report.Updated = append(report.Updated, moduleReport)
+		default:
+			report.Unchanged = append(report.Unchanged, moduleReport)
+		}
+		completed++
+		if opts.Progress != nil {
+			opts.Progress(UpdateProgress{
+				Completed: completed,
+				Total:     len(moduleAddrs),
+				Report:    moduleReport,
+			})
+		}
+	}
+
+	group := &errgroup.Group{}
+	group.SetLimit(opts.Workers)
+	var dispatchErr error
+dispatch:
+	for i, moduleAddr := range moduleAddrs {
+		if limiter != nil && i > 0 {
+			select {
+			case <-limiter:
+			case <-ctx.Done():
+				dispatchErr = ctx.Err()
+				break dispatch
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			dispatchErr = err
+			break
+		}
+		moduleAddr := moduleAddr
+		group.Go(func() error {
+			result, err := m.UpdateModule(ctx, moduleAddr)
+			record(ModuleUpdateReport{
+				Module: moduleAddr,
+				Result: result,
+				Error:  err,
+			})
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	for _, list := range [][]ModuleUpdateReport{report.Updated, report.Unchanged, report.Failed} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Module.String() < list[j].Module.String()
+		})
+	}
+	return report, dispatchErr
+}
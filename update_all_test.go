@@ -0,0 +1,88 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package libregistry_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opentofu/libregistry"
+	"github.com/opentofu/libregistry/metadata"
+	"github.com/opentofu/libregistry/metadata/storage/memory"
+	"github.com/opentofu/libregistry/types/module"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+// TestUpdateAllModules tests that a failing module does not stop the bulk update and that each module ends up in the
+// correct category of the report.
+func TestUpdateAllModules(t *testing.T) {
+	ctx := context.Background()
+	inMemoryVCS := fakevcs.New()
+	dataAPI, err := metadata.New(memory.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := libregistry.New(inMemoryVCS, dataAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inMemoryVCS.CreateOrganization("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := module.Addr{Namespace: "test", Name: "updated", TargetSystem: "aws"}
+	unchanged := module.Addr{Namespace: "test", Name: "unchanged", TargetSystem: "aws"}
+	missing := module.Addr{Namespace: "test", Name: "missing", TargetSystem: "aws"}
+	for _, moduleAddr := range []module.Addr{updated, unchanged} {
+		repo := moduleAddr.ToRepositoryAddr()
+		if err := inMemoryVCS.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := inMemoryVCS.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := registry.UpdateModule(ctx, moduleAddr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := inMemoryVCS.CreateVersion(updated.ToRepositoryAddr(), "v1.1.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataAPI.PutModule(ctx, missing, module.Metadata{Versions: module.VersionList{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var progressCalls atomic.Int32
+	report, err := registry.UpdateAllModules(ctx, libregistry.UpdateAllOptions{
+		Workers: 2,
+		Progress: func(progress libregistry.UpdateProgress) {
+			progressCalls.Add(1)
+			if progress.Total != 3 {
+				t.Errorf("Incorrect total in progress report: %d", progress.Total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progressCalls.Load() != 3 {
+		t.Fatalf("Incorrect number of progress calls: %d", progressCalls.Load())
+	}
+	if len(report.Updated) != 1 || !report.Updated[0].Module.Equals(updated) {
+		t.Fatalf("Incorrect updated modules: %v", report.Updated)
+	}
+	if added := report.Updated[0].Result.Added; len(added) != 1 || added[0] != "v1.1.0" {
+		t.Fatalf("Incorrect added versions: %v", added)
+	}
+	if len(report.Unchanged) != 1 || !report.Unchanged[0].Module.Equals(unchanged) {
+		t.Fatalf("Incorrect unchanged modules: %v", report.Unchanged)
+	}
+	if len(report.Failed) != 1 || !report.Failed[0].Module.Equals(missing) || report.Failed[0].Error == nil {
+		t.Fatalf("Incorrect failed modules: %v", report.Failed)
+	}
+}
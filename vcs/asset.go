@@ -5,6 +5,7 @@ package vcs
 
 import (
 	"regexp"
+	"strconv"
 )
 
 type AssetName string
@@ -61,3 +62,26 @@ func (a AssetNotFoundError) Error() string {
 func (a AssetNotFoundError) Unwrap() error {
 	return a.Cause
 }
+
+// AssetInfo describes an asset opened with Client.OpenAsset.
+type AssetInfo struct {
+	// Size is the size of the asset in bytes as reported by the VCS system, or -1 if the size is not known in
+	// advance.
+	Size int64
+	// ContentType is the MIME type of the asset as reported by the VCS system. This may be empty.
+	ContentType string
+}
+
+type AssetTooLargeError struct {
+	RepositoryAddr RepositoryAddr
+	Version        VersionNumber
+	Asset          AssetName
+	MaxSize        int64
+}
+
+func (a AssetTooLargeError) Error() string {
+	if a.Asset == "" {
+		return "Asset is larger than the maximum size of " + strconv.FormatInt(a.MaxSize, 10) + " bytes"
+	}
+	return "Asset " + string(a.Asset) + " in version " + string(a.Version) + " of repository " + a.RepositoryAddr.String() + " is larger than the maximum size of " + strconv.FormatInt(a.MaxSize, 10) + " bytes"
+}
@@ -0,0 +1,98 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package vcs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// OpenAssetWithLimit opens an asset using the client and wraps it in an AssetReader with the given maximum size. If
+// the VCS system reports a size larger than maxSize, this function returns an *AssetTooLargeError without reading the
+// asset. A maxSize of 0 or less disables the limit.
+func OpenAssetWithLimit(
+	ctx context.Context,
+	client Client,
+	repository RepositoryAddr,
+	version VersionNumber,
+	asset AssetName,
+	maxSize int64,
+) (*AssetReader, AssetInfo, error) {
+	stream, info, err := client.OpenAsset(ctx, repository, version, asset)
+	if err != nil {
+		return nil, AssetInfo{}, err
+	}
+	tooLargeErr := &AssetTooLargeError{
+		RepositoryAddr: repository,
+		Version:        version,
+		Asset:          asset,
+		MaxSize:        maxSize,
+	}
+	if maxSize > 0 && info.Size > maxSize {
+		_ = stream.Close()
+		return nil, info, tooLargeErr
+	}
+	reader := NewAssetReader(stream, maxSize)
+	reader.tooLargeErr = tooLargeErr
+	return reader, info, nil
+}
+
+// NewAssetReader wraps an asset stream, such as the one returned from Client.OpenAsset. The reader computes the
+// SHA-256 checksum of the data while it is read and returns an *AssetTooLargeError if the stream contains more than
+// maxSize bytes. A maxSize of 0 or less disables the limit.
+func NewAssetReader(stream io.ReadCloser, maxSize int64) *AssetReader {
+	return &AssetReader{
+		stream:      stream,
+		maxSize:     maxSize,
+		hash:        sha256.New(),
+		tooLargeErr: &AssetTooLargeError{MaxSize: maxSize},
+	}
+}
+
+// AssetReader reads an asset stream while enforcing a maximum size and computing the SHA-256 checksum.
+type AssetReader struct {
+	stream      io.ReadCloser
+	maxSize     int64
+	size        int64
+	hash        hash.Hash
+	tooLargeErr *AssetTooLargeError
+}
+
+func (a *AssetReader) Read(p []byte) (int, error) {
+	if a.maxSize > 0 {
+		if a.size > a.maxSize {
+			return 0, a.tooLargeErr
+		}
+		// Allow reading one byte over the limit to detect that the stream is too large.
+		if remaining := a.maxSize - a.size + 1; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+	n, err := a.stream.Read(p)
+	a.size += int64(n)
+	if a.maxSize > 0 && a.size > a.maxSize {
+		return 0, a.tooLargeErr
+	}
+	_, _ = a.hash.Write(p[:n])
+	return n, err
+}
+
+// Close closes the underlying stream.
+func (a *AssetReader) Close() error {
+	return a.stream.Close()
+}
+
+// Size returns the number of bytes read so far.
+func (a *AssetReader) Size() int64 {
+	return a.size
+}
+
+// SHA256 returns the hex-encoded SHA-256 checksum of the data read so far. Call this function after the reader
+// returned io.EOF to obtain the checksum of the whole asset.
+func (a *AssetReader) SHA256() string {
+	return hex.EncodeToString(a.hash.Sum(nil))
+}
@@ -0,0 +1,93 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package vcs_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+)
+
+func TestOpenAssetWithLimit(t *testing.T) {
+	const testAsset = "test.zip"
+	testData := []byte("Hello world!")
+	testChecksum := sha256.Sum256(testData)
+
+	repo := vcs.RepositoryAddr{
+		Org:  "test",
+		Name: "terraform-provider-test",
+	}
+	client := fakevcs.New()
+	if err := client.CreateOrganization(repo.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateRepository(repo, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateVersion(repo, "v1.0.0", os.DirFS(t.TempDir()).(fs.ReadDirFS)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddAsset(repo, "v1.0.0", testAsset, testData); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("within-limit", func(t *testing.T) {
+		reader, info, err := vcs.OpenAssetWithLimit(ctx, client, repo, "v1.0.0", testAsset, int64(len(testData)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		if info.Size != int64(len(testData)) {
+			t.Fatalf("Incorrect asset size reported: %d", info.Size)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(testData) {
+			t.Fatalf("Incorrect asset data: %s", data)
+		}
+		if reader.Size() != int64(len(testData)) {
+			t.Fatalf("Incorrect number of bytes read: %d", reader.Size())
+		}
+		if checksum := reader.SHA256(); checksum != hex.EncodeToString(testChecksum[:]) {
+			t.Fatalf("Incorrect checksum: %s", checksum)
+		}
+	})
+
+	t.Run("reported-size-over-limit", func(t *testing.T) {
+		_, _, err := vcs.OpenAssetWithLimit(ctx, client, repo, "v1.0.0", testAsset, int64(len(testData))-1)
+		var tooLarge *vcs.AssetTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("Incorrect error returned: %v", err)
+		}
+	})
+
+	t.Run("stream-over-limit", func(t *testing.T) {
+		stream, _, err := client.OpenAsset(ctx, repo, "v1.0.0", testAsset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := vcs.NewAssetReader(stream, 5)
+		defer func() {
+			_ = reader.Close()
+		}()
+		_, err = io.ReadAll(reader)
+		var tooLarge *vcs.AssetTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("Incorrect error returned: %v", err)
+		}
+	})
+}
@@ -5,6 +5,7 @@ package vcs
 
 import (
 	"context"
+	"io"
 	"io/fs"
 )
 
@@ -53,9 +54,15 @@ type Client interface {
 	// ListAssets lists all binary assets for a release of a repository.
 	ListAssets(ctx context.Context, repository RepositoryAddr, version VersionNumber) ([]AssetName, error)
 
-	// DownloadAsset downloads a given asset from a release in a repository.
+	// DownloadAsset downloads a given asset from a release in a repository. This call reads the whole asset into
+	// memory, prefer OpenAsset for large assets.
 	DownloadAsset(ctx context.Context, repository RepositoryAddr, version VersionNumber, asset AssetName) ([]byte, error)
 
+	// OpenAsset opens a given asset from a release in a repository for streaming. The caller is responsible for
+	// closing the returned reader. Use OpenAssetWithLimit to enforce a maximum size and compute the checksum while
+	// reading.
+	OpenAsset(ctx context.Context, repository RepositoryAddr, version VersionNumber, asset AssetName) (io.ReadCloser, AssetInfo, error)
+
 	// HasPermission returns true if the user has permission to act on behalf of an organization.
 	HasPermission(ctx context.Context, username Username, organization OrganizationAddr) (bool, error)
 
@@ -63,10 +70,15 @@ type Client interface {
 	// in the repository. The caller is responsible for calling Close on the WorkingCopy when finished to allow a
 	// cleanup or release any locks.
 	//
-	// Note that the implementation may limit the concurrent use of a repository to a single WorkingCopy at a time in
-	// order to adhere to any rate limits the VCS system may impose.
+	// Note that the implementation may limit the concurrent use of a repository or a version to a single WorkingCopy
+	// at a time in order to adhere to any rate limits the VCS system may impose.
 	Checkout(ctx context.Context, repository RepositoryAddr, version VersionNumber) (WorkingCopy, error)
 
+	// SparseCheckout works like Checkout, but only requests the files in the specified directories to be checked out.
+	// Directories are relative to the repository root and use forward slashes. The implementation may check out more
+	// files than requested, up to the full repository, so callers must not rely on other files being absent.
+	SparseCheckout(ctx context.Context, repository RepositoryAddr, version VersionNumber, directories ...string) (WorkingCopy, error)
+
 	// GetRepositoryBrowseURL returns the web address the repository can be viewed at. The implementation may return
 	// a *NoWebAccessError if the VCS system does not support accessing files via the web.
 	GetRepositoryBrowseURL(ctx context.Context, repository RepositoryAddr) (string, error)
@@ -106,4 +118,7 @@ type RepositoryInfo struct {
 	ForkOf *RepositoryAddr `json:"fork_of,omitempty"`
 	// ForkCount exposes the amount of copies/forks present in the VCS.
 	ForkCount int
+	// MovedTo holds the new address if the repository was renamed or transferred and the VCS system redirected the
+	// request. This is empty if the repository is still at the requested address.
+	MovedTo *RepositoryAddr `json:"moved_to,omitempty"`
 }
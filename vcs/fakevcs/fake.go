@@ -26,6 +26,7 @@ func NewWithOpts(opts ...Opt) (VCSClient, error) {
 		config:        cfg,
 		users:         map[vcs.Username]struct{}{},
 		organizations: map[vcs.OrganizationAddr]*org{},
+		moved:         map[vcs.RepositoryAddr]vcs.RepositoryAddr{},
 	}, nil
 }
 
@@ -62,6 +63,9 @@ type VCSClient interface {
 
 	CreateOrganization(organization vcs.OrganizationAddr) error
 	CreateRepository(repository vcs.RepositoryAddr, info vcs.RepositoryInfo) error
+	// MoveRepository renames or transfers a repository. Afterwards, GetRepositoryInfo on the old address reports the
+	// new address in MovedTo, while all other calls on the old address fail with a *vcs.RepositoryNotFoundError.
+	MoveRepository(from vcs.RepositoryAddr, to vcs.RepositoryAddr) error
 	CreateVersion(repository vcs.RepositoryAddr, version vcs.VersionNumber, content fs.ReadDirFS) error
 	AddAsset(repository vcs.RepositoryAddr, version vcs.VersionNumber, name vcs.AssetName, data []byte) error
 	AddUser(username vcs.Username) error
@@ -4,8 +4,12 @@
 package fakevcs
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"io/fs"
 	"strings"
 
@@ -16,6 +20,8 @@ type inMemoryVCS struct {
 	config        Config
 	users         map[vcs.Username]struct{}
 	organizations map[vcs.OrganizationAddr]*org
+	// moved maps the old addresses of moved repositories to their new addresses.
+	moved map[vcs.RepositoryAddr]vcs.RepositoryAddr
 }
 
 func (i *inMemoryVCS) GetTagVersion(ctx context.Context, repositoryAddr vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
@@ -41,6 +47,7 @@ func (i *inMemoryVCS) GetTagVersion(ctx context.Context, repositoryAddr vcs.Repo
 			return vcs.Version{
 				VersionNumber: ver.name,
 				Created:       ver.created,
+				Commit:        ver.commit,
 			}, nil
 		}
 	}
@@ -67,6 +74,11 @@ func (i *inMemoryVCS) GetRepositoryInfo(_ context.Context, repositoryAddr vcs.Re
 		return vcs.RepositoryInfo{}, err
 	}
 
+	var movedTo *vcs.RepositoryAddr
+	if target, ok := i.moved[repositoryAddr]; ok {
+		movedTo = &target
+		repositoryAddr = target
+	}
 	org, ok := i.organizations[repositoryAddr.Org]
 	if !ok {
 		return vcs.RepositoryInfo{}, &vcs.RepositoryNotFoundError{
@@ -80,7 +92,9 @@ func (i *inMemoryVCS) GetRepositoryInfo(_ context.Context, repositoryAddr vcs.Re
 		}
 	}
 
-	return repo.info, nil
+	info := repo.info
+	info.MovedTo = movedTo
+	return info, nil
 }
 
 func (i *inMemoryVCS) ListLatestReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
@@ -110,6 +124,7 @@ func (i *inMemoryVCS) ListAllReleases(_ context.Context, repositoryAddr vcs.Repo
 		result[i] = vcs.Version{
 			VersionNumber: ver.name,
 			Created:       ver.created,
+			Commit:        ver.commit,
 		}
 	}
 	return result, nil
@@ -160,6 +175,7 @@ func (i *inMemoryVCS) ListAllTags(_ context.Context, repositoryAddr vcs.Reposito
 		result[i] = vcs.Version{
 			VersionNumber: ver.name,
 			Created:       ver.created,
+			Commit:        ver.commit,
 		}
 	}
 	return result, nil
@@ -237,6 +253,17 @@ func (i *inMemoryVCS) DownloadAsset(_ context.Context, repositoryAddr vcs.Reposi
 	}
 }
 
+func (i *inMemoryVCS) OpenAsset(ctx context.Context, repositoryAddr vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (io.ReadCloser, vcs.AssetInfo, error) {
+	assetData, err := i.DownloadAsset(ctx, repositoryAddr, version, asset)
+	if err != nil {
+		return nil, vcs.AssetInfo{}, err
+	}
+	return io.NopCloser(bytes.NewReader(assetData)), vcs.AssetInfo{
+		Size:        int64(len(assetData)),
+		ContentType: "application/octet-stream",
+	}, nil
+}
+
 func (i *inMemoryVCS) HasPermission(_ context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
 	if err := organization.Validate(); err != nil {
 		return false, err
@@ -282,7 +309,51 @@ func (i *inMemoryVCS) CreateRepository(repositoryAddr vcs.RepositoryAddr, reposi
 			repositoryAddr,
 		}
 	}
-	i.organizations[repositoryAddr.Org].repositories[repositoryAddr] = &repository{}
+	i.organizations[repositoryAddr.Org].repositories[repositoryAddr] = &repository{
+		info: repositoryInfo,
+	}
+	return nil
+}
+
+func (i *inMemoryVCS) MoveRepository(from vcs.RepositoryAddr, to vcs.RepositoryAddr) error {
+	if err := from.Validate(); err != nil {
+		return err
+	}
+	if err := to.Validate(); err != nil {
+		return err
+	}
+	sourceOrg, ok := i.organizations[from.Org]
+	if !ok {
+		return &vcs.RepositoryNotFoundError{
+			RepositoryAddr: from,
+		}
+	}
+	repo, ok := sourceOrg.repositories[from]
+	if !ok {
+		return &vcs.RepositoryNotFoundError{
+			RepositoryAddr: from,
+		}
+	}
+	targetOrg, ok := i.organizations[to.Org]
+	if !ok {
+		return &vcs.OrganizationNotFoundError{
+			OrganizationAddr: to.Org,
+		}
+	}
+	if _, ok := targetOrg.repositories[to]; ok {
+		return &RepositoryAlreadyExistsError{
+			to,
+		}
+	}
+	delete(sourceOrg.repositories, from)
+	targetOrg.repositories[to] = repo
+	for old, target := range i.moved {
+		if target == from {
+			i.moved[old] = to
+		}
+	}
+	delete(i.moved, to)
+	i.moved[from] = to
 	return nil
 }
 
@@ -317,6 +388,7 @@ func (i *inMemoryVCS) CreateVersion(repositoryAddr vcs.RepositoryAddr, versionNa
 		{
 			name:     versionName,
 			created:  i.config.TimeSource(),
+			commit:   fakeCommit(repositoryAddr, versionName),
 			assets:   map[vcs.AssetName][]byte{},
 			contents: contents,
 		},
@@ -324,6 +396,12 @@ func (i *inMemoryVCS) CreateVersion(repositoryAddr vcs.RepositoryAddr, versionNa
 	return nil
 }
 
+// fakeCommit returns a stable, fake commit SHA for a version.
+func fakeCommit(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber) string {
+	sum := sha1.Sum([]byte(repositoryAddr.String() + "@" + string(versionName))) //nolint:gosec // Not used for security.
+	return hex.EncodeToString(sum[:])
+}
+
 func (i *inMemoryVCS) AddAsset(repositoryAddr vcs.RepositoryAddr, versionName vcs.VersionNumber, assetName vcs.AssetName, assetData []byte) error {
 	if err := repositoryAddr.Validate(); err != nil {
 		return err
@@ -429,6 +507,11 @@ func (i *inMemoryVCS) Checkout(ctx context.Context, repositoryAddr vcs.Repositor
 	}
 }
 
+func (i *inMemoryVCS) SparseCheckout(ctx context.Context, repositoryAddr vcs.RepositoryAddr, version vcs.VersionNumber, _ ...string) (vcs.WorkingCopy, error) {
+	// The fake VCS always returns the full contents, which the interface allows.
+	return i.Checkout(ctx, repositoryAddr, version)
+}
+
 type workingCopy struct {
 	fs.ReadDirFS
 	client         *inMemoryVCS
@@ -13,6 +13,7 @@ import (
 type version struct {
 	name     vcs.VersionNumber
 	created  time.Time
+	commit   string
 	assets   map[vcs.AssetName][]byte
 	contents fs.ReadDirFS
 }
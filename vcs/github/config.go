@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 
 	"github.com/opentofu/libregistry/logger"
@@ -27,9 +28,27 @@ type Config struct {
 	// CheckoutRootDirectory is the root directory where repositories should be checked out. Defaults to the OS' temp
 	// directory.
 	CheckoutRootDirectory string
+	// MirrorDirectory is the directory where the bare, partial mirrors of repositories are cached. Working copies are
+	// created from these mirrors as git worktrees. Mirrors are kept across calls to avoid re-cloning the repository.
+	// Defaults to the ".mirrors" directory inside the CheckoutRootDirectory.
+	MirrorDirectory string
 	// SkipCleanupWorkingCopyOnClose indicates that the working copy should not be cleaned up when it is closed.
 	// Defaults to false, cleaning up the working copy.
 	SkipCleanupWorkingCopyOnClose bool
+	// MaxWorkingCopies limits how many working copies can be checked out at the same time across all repositories.
+	// Further calls to Checkout wait until a working copy is closed. Defaults to 0, meaning no limit.
+	MaxWorkingCopies int
+	// MaxWorkingCopiesPerRepository limits how many working copies (of different versions) can be checked out from
+	// a single repository at the same time. Defaults to 0, meaning no limit.
+	MaxWorkingCopiesPerRepository int
+	// MaxRetainedWorkingCopies limits how many closed working copies are kept on disk when
+	// SkipCleanupWorkingCopyOnClose is set. The least recently used working copies are removed first. Defaults to 0,
+	// meaning no limit.
+	MaxRetainedWorkingCopies int
+	// MaxWorkingCopyDiskUsage limits the disk space in bytes used by closed working copies kept on disk when
+	// SkipCleanupWorkingCopyOnClose is set. The least recently used working copies are removed first. Working copies
+	// retained by a previous process are not accounted for. Defaults to 0, meaning no limit.
+	MaxWorkingCopyDiskUsage int64
 	// GitPath holds the path to the git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
 	GitPath string
 
@@ -46,6 +65,11 @@ func (c *Config) ApplyDefaults() {
 		c.CheckoutRootDirectory = os.TempDir()
 	}
 
+	if c.MirrorDirectory == "" {
+		// Organization names cannot start with a dot, so this directory cannot conflict with checkouts.
+		c.MirrorDirectory = path.Join(c.CheckoutRootDirectory, ".mirrors")
+	}
+
 	if c.GitPath == "" {
 		c.GitPath = defaultGitPath
 	}
@@ -100,6 +124,21 @@ func WithCheckoutRootDirectory(rootDir string) Opt {
 	}
 }
 
+// WithMirrorDirectory sets a directory to use for caching repository mirrors.
+func WithMirrorDirectory(mirrorDir string) Opt {
+	return func(config *Config) error {
+		if err := os.MkdirAll(mirrorDir, 0700); err != nil {
+			return fmt.Errorf("unusable mirror directory (%w)", err)
+		}
+		mirrorDir, err := filepath.Abs(mirrorDir)
+		if err != nil {
+			return fmt.Errorf("failed to determine absolute path for %s (%v)", mirrorDir, err)
+		}
+		config.MirrorDirectory = mirrorDir
+		return nil
+	}
+}
+
 // WithSkipCleanupWorkingCopyOnClose skips cleaning up the working directory when it is closed. This is useful when
 // wanting to re-use the working directory and skip re-cloning the repository.
 func WithSkipCleanupWorkingCopyOnClose(skip bool) Opt {
@@ -109,6 +148,54 @@ func WithSkipCleanupWorkingCopyOnClose(skip bool) Opt {
 	}
 }
 
+// WithMaxWorkingCopies limits how many working copies can be checked out at the same time across all repositories.
+// Pass 0 to disable the limit.
+func WithMaxWorkingCopies(maxWorkingCopies int) Opt {
+	return func(config *Config) error {
+		if maxWorkingCopies < 0 {
+			return fmt.Errorf("the maximum number of working copies cannot be negative")
+		}
+		config.MaxWorkingCopies = maxWorkingCopies
+		return nil
+	}
+}
+
+// WithMaxWorkingCopiesPerRepository limits how many working copies can be checked out from a single repository at
+// the same time. Pass 0 to disable the limit.
+func WithMaxWorkingCopiesPerRepository(maxWorkingCopies int) Opt {
+	return func(config *Config) error {
+		if maxWorkingCopies < 0 {
+			return fmt.Errorf("the maximum number of working copies per repository cannot be negative")
+		}
+		config.MaxWorkingCopiesPerRepository = maxWorkingCopies
+		return nil
+	}
+}
+
+// WithMaxRetainedWorkingCopies limits how many closed working copies are kept on disk when the cleanup is skipped.
+// Pass 0 to disable the limit.
+func WithMaxRetainedWorkingCopies(maxRetained int) Opt {
+	return func(config *Config) error {
+		if maxRetained < 0 {
+			return fmt.Errorf("the maximum number of retained working copies cannot be negative")
+		}
+		config.MaxRetainedWorkingCopies = maxRetained
+		return nil
+	}
+}
+
+// WithMaxWorkingCopyDiskUsage limits the disk space in bytes closed working copies may use when the cleanup is
+// skipped. Pass 0 to disable the limit.
+func WithMaxWorkingCopyDiskUsage(maxBytes int64) Opt {
+	return func(config *Config) error {
+		if maxBytes < 0 {
+			return fmt.Errorf("the maximum working copy disk usage cannot be negative")
+		}
+		config.MaxWorkingCopyDiskUsage = maxBytes
+		return nil
+	}
+}
+
 // WithGitPath sets the path to the Git binary. Defaults to looking up the "git" or "git.exe" binaries in the path.
 func WithGitPath(path string) Opt {
 	return func(config *Config) error {
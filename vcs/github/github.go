@@ -4,7 +4,6 @@
 package github
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"encoding/xml"
@@ -24,8 +23,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/opentofu/libregistry/internal/retry"
-
 	"github.com/opentofu/libregistry/logger"
 	"github.com/opentofu/libregistry/vcs"
 )
@@ -43,16 +40,21 @@ func New(
 	config.ApplyDefaults()
 
 	return &github{
-		config: config,
-		lock:   &sync.Mutex{},
-		locks:  map[string]*sync.Mutex{},
+		config:  config,
+		lock:    &sync.Mutex{},
+		locks:   map[string]*directoryLock{},
+		fetched: map[string]time.Time{},
+		pool:    newWorkingCopyPool(config),
 	}, nil
 }
 
 type github struct {
 	config Config
 	lock   *sync.Mutex
-	locks  map[string]*sync.Mutex
+	locks  map[string]*directoryLock
+	// fetched holds the time the tags of each mirror were last fetched, keyed by the mirror directory.
+	fetched map[string]time.Time
+	pool    *workingCopyPool
 }
 
 func (g github) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
@@ -63,12 +65,12 @@ func (g github) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr
 		return vcs.Version{}, err
 	}
 
-	wc, err := g.getWorkingCopy(ctx, repository)
+	m, err := g.getMirror(ctx, repository)
 	if err != nil {
 		return vcs.Version{}, err
 	}
-	defer wc.cleanup()
-	return wc.getTag(ctx, version)
+	defer m.release()
+	return m.getTag(ctx, version)
 }
 
 func (g github) GetRepositoryBrowseURL(_ context.Context, repository vcs.RepositoryAddr) (string, error) {
@@ -110,6 +112,10 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 		return vcs.RepositoryInfo{}, err
 	}
 	type repoInfoResponse struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
 		Description     string `json:"description"`
 		StargazersCount int    `json:"stargazers_count"`
 		ForkCount       int    `json:"forks_count"`
@@ -138,127 +144,123 @@ func (g github) GetRepositoryInfo(ctx context.Context, repository vcs.Repository
 			Name: response.Parent.Name,
 		}
 	}
+	// GitHub redirects requests for renamed and transferred repositories, which the HTTP client follows, so the
+	// response describes the repository at its new address. Names are case-insensitive.
+	if response.Name != "" && (!strings.EqualFold(response.Owner.Login, string(repository.Org)) || !strings.EqualFold(response.Name, repository.Name)) {
+		repoInfo.MovedTo = &vcs.RepositoryAddr{
+			Org:  vcs.OrganizationAddr(response.Owner.Login),
+			Name: response.Name,
+		}
+	}
 	return repoInfo, nil
 }
 
 func (g github) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
+	return g.checkout(ctx, repository, version, nil)
+}
+
+func (g github) SparseCheckout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, directories ...string) (vcs.WorkingCopy, error) {
+	for _, directory := range directories {
+		if directory == "." || !fs.ValidPath(directory) {
+			return nil, fmt.Errorf("invalid sparse checkout directory: %s", directory)
+		}
+	}
+	return g.checkout(ctx, repository, version, directories)
+}
+
+func (g github) checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, directories []string) (vcs.WorkingCopy, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
 	if err := version.Validate(); err != nil {
 		return nil, err
 	}
-	wc, err := g.getWorkingCopy(ctx, repository)
+
+	// Each version gets its own worktree, so only checkouts of the same version need to wait for each other. The
+	// directory lock is taken before the slot, so a checkout waiting for the same version does not hold a slot.
+	checkoutDirectory := path.Join(g.config.CheckoutRootDirectory, string(repository.Org), repository.Name, url.PathEscape(string(version)))
+	unlockDirectory, err := g.lockDirectory(ctx, checkoutDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to wait for the working copy directory: %w", err))
+	}
+
+	releaseSlot, err := g.pool.acquire(ctx, repository)
+	if err != nil {
+		unlockDirectory()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to wait for a free working copy slot: %w", err))
+	}
+	unlock := func() {
+		unlockDirectory()
+		releaseSlot()
+	}
+	g.pool.take(checkoutDirectory)
+
+	// The mirror is only locked while registering the worktree. The checkout itself fetches the file contents, which
+	// can take a while, so it runs without the lock to let other checkouts of the repository proceed.
+	m, err := g.getMirror(ctx, repository)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to get working copy: %w", err))
+		unlock()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to get mirror: %w", err))
 	}
 
-	tagExists, err := wc.tagExists(ctx, version)
+	tagExists, err := m.tagExists(ctx, version)
 	if err != nil {
-		wc.cleanup()
+		m.release()
+		unlock()
 		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check if tag %s exists: %w", version, err))
 	}
 	if !tagExists {
-		wc.cleanup()
+		m.release()
+		unlock()
 		return nil, &vcs.VersionNotFoundError{
 			RepositoryAddr: repository,
 			Version:        version,
 		}
 	}
 
-	if err := wc.reset(ctx); err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to reset repository: %w", err))
-	}
-
-	if err := wc.clean(ctx); err != nil {
-		wc.cleanup()
-		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to clean repository: %w", err))
-	}
-
-	if err := wc.checkout(ctx, version); err != nil {
-		wc.cleanup()
+	reused, err := m.addWorktree(ctx, checkoutDirectory, version)
+	if err != nil {
+		if e := m.removeWorktree(ctx, checkoutDirectory); e != nil {
+			g.config.Logger.Debug(ctx, "Failed to clean up worktree at %s (%v)", checkoutDirectory, e)
+		}
+		m.release()
+		unlock()
 		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check out tag %s: %w", version, err))
 	}
-	return wc, nil
-}
+	m.release()
 
-func (g github) getWorkingCopy(ctx context.Context, repository vcs.RepositoryAddr) (*workingCopy, error) {
-	if err := repository.Validate(); err != nil {
-		return nil, err
+	if err := g.checkoutWorktree(ctx, checkoutDirectory, version, directories, reused); err != nil {
+		if e := g.removeWorktree(context.WithoutCancel(ctx), repository, checkoutDirectory); e != nil {
+			g.config.Logger.Debug(ctx, "Failed to clean up worktree at %s (%v)", checkoutDirectory, e)
+		}
+		unlock()
+		return nil, fmt.Errorf("failed to check out %s: %w", repository, fmt.Errorf("failed to check out tag %s: %w", version, err))
 	}
-	parentDirectory := path.Join(g.config.CheckoutRootDirectory, string(repository.Org))
-	checkoutDirectory := path.Join(parentDirectory, repository.Name)
-	gitDirectory := path.Join(checkoutDirectory, ".git")
 
-	g.lock.Lock()
-	lock, ok := g.locks[checkoutDirectory]
-	if !ok {
-		lock = &sync.Mutex{}
-		g.locks[checkoutDirectory] = lock
-	}
-	g.lock.Unlock()
-	lock.Lock()
-	cleanup := func() {
-		g.lock.Lock()
+	cleanupCtx := context.WithoutCancel(ctx)
+	return &workingCopy{
+		ReadDirFS:  os.DirFS(checkoutDirectory).(fs.ReadDirFS),
+		repository: repository,
+		version:    version,
+		dir:        checkoutDirectory,
+		cleanup: func() {
+			if g.config.SkipCleanupWorkingCopyOnClose {
+				g.pool.retain(repository, checkoutDirectory)
+				unlock()
+				g.evictRetainedWorkingCopies(cleanupCtx)
+				return
+			}
 
-		if !g.config.SkipCleanupWorkingCopyOnClose {
 			// Make sure that any open file descriptors are closed before cleaning up the directory so Windows file
 			// locking doesn't block the cleanup:
 			runtime.GC()
 
-			if err := os.RemoveAll(checkoutDirectory); err != nil {
-				g.config.Logger.Debug(ctx, "Failed to clean up clone repository at %s (%v)", checkoutDirectory, err)
-			}
-		}
-
-		delete(g.locks, checkoutDirectory)
-		lock.Unlock()
-		g.lock.Unlock()
-	}
-
-	stat, err := os.Stat(gitDirectory)
-	if err != nil || !stat.IsDir() {
-		if err := os.RemoveAll(checkoutDirectory); err != nil {
-			cleanup()
-			return nil, fmt.Errorf("failed to remove broken checkout directory %s (%w)", checkoutDirectory, err)
-		}
-		if err := os.MkdirAll(parentDirectory, 0700); err != nil {
-			cleanup()
-			return nil, fmt.Errorf("failed to create checkout parent directory %s (%w)", parentDirectory, err)
-		}
-		credentials := ""
-		if g.config.Username != "" && g.config.Token != "" {
-			credentials = url.PathEscape(g.config.Username) + ":" + url.PathEscape(g.config.Token) + "@"
-		}
-
-		cloneURL := "https://" + credentials + "github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + ".git"
-		if err := g.git(ctx, parentDirectory, nil, "clone", "--depth", "1", cloneURL, checkoutDirectory); err != nil {
-			cleanup()
-
-			// Clone failed, check if repository exists.
-			repoExists, e := g.repositoryExists(ctx, repository)
-			if e == nil && !repoExists {
-				return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
+			if err := g.removeWorktree(cleanupCtx, repository, checkoutDirectory); err != nil {
+				g.config.Logger.Debug(cleanupCtx, "Failed to clean up worktree at %s (%v)", checkoutDirectory, err)
 			}
-
-			return nil, err
-		}
-	}
-
-	if err := g.git(ctx, checkoutDirectory, nil, "fetch", "--tags", "--force"); err != nil {
-		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
-			cleanup()
-			return nil, &vcs.RepositoryNotFoundError{RepositoryAddr: repository, Cause: err}
-		}
-		cleanup()
-		return nil, err
-	}
-
-	return &workingCopy{
-		ReadDirFS:  os.DirFS(checkoutDirectory).(fs.ReadDirFS),
-		repository: repository,
-		dir:        checkoutDirectory,
-		cleanup:    cleanup,
-		g:          g,
+			unlock()
+		},
+		g: g,
 	}, nil
 }
 
@@ -297,140 +299,6 @@ func is128Retryable(err error) bool {
 	return errors.As(err, &exitErr) && exitErr.ExitCode() == 128
 }
 
-func (w *workingCopy) checkout(ctx context.Context, version vcs.VersionNumber) error {
-	if err := retry.Func(
-		ctx,
-		"git checkout "+string(version),
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "checkout", string(version))
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	); err != nil {
-		if !is128Retryable(err) {
-			// Checkout failed, see if tag exists.
-			tagExists, e := w.tagExists(ctx, version)
-			if e == nil && !tagExists {
-				return &vcs.VersionNotFoundError{Version: version, RepositoryAddr: w.repository, Cause: err}
-			}
-
-		}
-		return err
-	}
-	w.version = version
-	return nil
-}
-
-func (w *workingCopy) reset(ctx context.Context) error {
-	return retry.Func(
-		ctx,
-		"git reset --hard",
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "reset", "--hard")
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
-func (w *workingCopy) clean(ctx context.Context) error {
-	return retry.Func(
-		ctx,
-		"git clean -fd",
-		func() error {
-			return w.g.git(ctx, w.dir, nil, "clean", "-fd")
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
-func (w *workingCopy) tagExists(ctx context.Context, version vcs.VersionNumber) (bool, error) {
-	tags, err := w.listTags(ctx)
-	if err != nil {
-		return false, err
-	}
-	for _, tag := range tags {
-		if tag.VersionNumber.Equals(version) {
-			return true, nil
-		}
-	}
-	return false, nil
-}
-
-func (w *workingCopy) getTag(ctx context.Context, tag vcs.VersionNumber) (vcs.Version, error) {
-	tags, err := w.listTags(ctx)
-	if err != nil {
-		return vcs.Version{}, err
-	}
-	for _, t := range tags {
-		if t.VersionNumber.Equals(tag) {
-			return t, nil
-		}
-	}
-	return vcs.Version{}, &vcs.VersionNotFoundError{
-		RepositoryAddr: w.repository,
-		Version:        tag,
-	}
-}
-
-func (w *workingCopy) listTags(ctx context.Context) ([]vcs.Version, error) {
-	stdout, err := w.listRefs(ctx)
-	if err != nil {
-		return nil, err
-	}
-	lines := strings.Split(stdout.String(), "\n")
-	var result []vcs.Version
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, "\t", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("line does not contain enough parts to parse: %s", line)
-		}
-		tag := vcs.VersionNumber(strings.ReplaceAll(parts[0], "refs/tags/", ""))
-		unixTime, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse git output: %s (%v)", line, err)
-		}
-		created := time.Unix(int64(unixTime), 0)
-		ver := vcs.Version{
-			VersionNumber: tag,
-			Created:       created,
-		}
-		if err := ver.Validate(); err != nil {
-			w.g.config.Logger.Debug(ctx, "Skipping tag %s because it does not match the naming rules.", ver.VersionNumber)
-			continue
-		}
-		result = append(result, ver)
-	}
-	return result, nil
-}
-
-func (w *workingCopy) listRefs(ctx context.Context) (*bytes.Buffer, error) {
-	return retry.Func2(
-		ctx,
-		"git for-each-ref",
-		func() (*bytes.Buffer, error) {
-			stdout := &bytes.Buffer{}
-			err := w.g.git(ctx, w.dir, stdout, "for-each-ref", "--format=%(refname:short)\t%(creatordate:format:%s)", "refs/tags/*")
-			return stdout, err
-		},
-		is128Retryable,
-		10,
-		100*time.Millisecond,
-		w.g.config.Logger,
-	)
-}
-
 func (g github) git(ctx context.Context, dir string, stdout io.Writer, params ...string) error {
 	params = append([]string{"-c", "credential.helper="}, params...)
 	cmd := exec.Command(g.config.GitPath, params...)
@@ -442,7 +310,7 @@ func (g github) git(ctx context.Context, dir string, stdout io.Writer, params ..
 	cmd.Stdout = stdout
 	cmd.Stderr = logger.NewWriter(ctx, g.config.Logger, logger.LevelDebug, commandString+": ")
 	cmd.Dir = dir
-	cmd.Env = []string{"GIT_TERMINAL_PROMPT=0"}
+	cmd.Env = g.gitEnv()
 	done := make(chan struct{})
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to run %s (%w)", commandString, err)
@@ -569,12 +437,12 @@ func (g github) listLatest(ctx context.Context, repository vcs.RepositoryAddr, f
 func (g github) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
 	logger.LogTrace(ctx, g.config.Logger, "Requesting all tags for repository %s...", repository)
 
-	wc, err := g.getWorkingCopy(ctx, repository)
+	m, err := g.getMirror(ctx, repository)
 	if err != nil {
 		return nil, err
 	}
-	defer wc.cleanup()
-	return wc.listTags(ctx)
+	defer m.release()
+	return m.listTags(ctx)
 }
 
 func (g github) ListAllReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
@@ -716,20 +584,38 @@ func (g github) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, v
 }
 
 func (g github) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) ([]byte, error) {
-	if err := repository.Validate(); err != nil {
+	stream, _, err := g.OpenAsset(ctx, repository, version, asset)
+	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, &vcs.RequestFailedError{
+			Cause: err,
+		}
+	}
+	return body, nil
+}
+
+func (g github) OpenAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (io.ReadCloser, vcs.AssetInfo, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, vcs.AssetInfo{}, err
+	}
 	if err := version.Validate(); err != nil {
-		return nil, err
+		return nil, vcs.AssetInfo{}, err
 	}
 	if err := asset.Validate(); err != nil {
-		return nil, err
+		return nil, vcs.AssetInfo{}, err
 	}
-	logger.LogTrace(ctx, g.config.Logger, "Listing asset %s for repository %s version %s", asset, repository, version)
+	logger.LogTrace(ctx, g.config.Logger, "Opening asset %s for repository %s version %s", asset, repository, version)
 	assetURL := "https://api.github.com/repos/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + "/releases/download/" + url.PathEscape(string(version)) + "/" + url.PathEscape(string(asset))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
 	if err != nil {
-		return nil, &vcs.RequestFailedError{
+		return nil, vcs.AssetInfo{}, &vcs.RequestFailedError{
 			Cause: fmt.Errorf("invalid HTTP request (%w)", err),
 		}
 	}
@@ -740,36 +626,31 @@ func (g github) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr
 	resp, err := g.config.HTTPClient.Do(req)
 	if err != nil {
 		logger.LogTrace(ctx, g.config.Logger, "GET request to %s failed (%v)", assetURL, err)
-		return nil, &vcs.RequestFailedError{
+		return nil, vcs.AssetInfo{}, &vcs.RequestFailedError{
 			Cause: err,
 		}
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 	logger.LogTrace(ctx, g.config.Logger, "GET request to %s returned status code %d", assetURL, resp.StatusCode)
 	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
 		err = &InvalidStatusCodeError{resp.StatusCode}
 		if resp.StatusCode == http.StatusNotFound {
-			return nil, &vcs.AssetNotFoundError{
+			return nil, vcs.AssetInfo{}, &vcs.AssetNotFoundError{
 				RepositoryAddr: repository,
 				Version:        version,
 				Asset:          asset,
 				Cause:          err,
 			}
 		}
-		return nil, &vcs.RequestFailedError{
+		return nil, vcs.AssetInfo{}, &vcs.RequestFailedError{
 			Cause: err,
 		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, &vcs.RequestFailedError{
-			Cause: err,
-		}
-	}
-	return body, nil
+	return resp.Body, vcs.AssetInfo{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
 }
 
 func (g github) HasPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
@@ -7,7 +7,10 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net/http"
 	"os"
+	"path"
+	"strings"
 	"testing"
 	"time"
 
@@ -180,6 +183,54 @@ func TestClone(t *testing.T) {
 	}
 }
 
+// TestCloneLegacyLayout tests that a plain clone left in the repository directory by an earlier version of the library,
+// which now holds one worktree per version, is removed before checking out.
+func TestCloneLegacyLayout(t *testing.T) {
+	t.Parallel()
+
+	const testOrg = "opentofu"
+	const testRepo = "terraform-provider-tfcoremock"
+	const testVersion = "v0.3.0"
+
+	checkoutDir := t.TempDir()
+	legacyDir := path.Join(checkoutDir, testOrg, testRepo)
+	if err := os.MkdirAll(path.Join(legacyDir, ".git"), 0700); err != nil {
+		t.Fatalf("Failed to create legacy checkout (%v)", err)
+	}
+	if err := os.WriteFile(path.Join(legacyDir, "README.md"), []byte("legacy"), 0600); err != nil {
+		t.Fatalf("Failed to create legacy checkout (%v)", err)
+	}
+
+	gh, err := github.New(
+		github.WithCheckoutRootDirectory(checkoutDir),
+		github.WithLogger(logger.NewTestLogger(t)),
+		github.WithToken(os.Getenv("GITHUB_TOKEN")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	workingCopy, err := gh.Checkout(ctx, vcs.RepositoryAddr{
+		Org:  testOrg,
+		Name: testRepo,
+	}, testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = workingCopy.Close()
+	})
+	if _, err := os.Stat(path.Join(legacyDir, ".git")); !os.IsNotExist(err) {
+		t.Fatalf("❌ The legacy checkout was not removed.")
+	}
+	if _, err := os.Stat(path.Join(legacyDir, "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("❌ The legacy checkout was not removed.")
+	}
+	if _, err := workingCopy.Open("README.md"); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestCloneNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -262,3 +313,48 @@ func TestURL(t *testing.T) {
 	}
 	t.Logf("✅ The file view URL is correct: %s", fileURL)
 }
+
+// redirectingTransport emulates the GitHub API redirecting requests for a renamed repository.
+type redirectingTransport struct{}
+
+func (redirectingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := http.Header{}
+	switch req.URL.Path {
+	case "/repos/old-org/terraform-aws-old":
+		header.Set("Location", "https://api.github.com/repositories/42")
+		return &http.Response{StatusCode: http.StatusMovedPermanently, Header: header, Body: http.NoBody, Request: req}, nil
+	case "/repositories/42", "/repos/New-Org/terraform-aws-new":
+		header.Set("Content-Type", "application/json")
+		body := `{"name":"terraform-aws-new","owner":{"login":"new-org"},"description":"Moved"}`
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: http.NoBody, Request: req}, nil
+	}
+}
+
+func TestRepoInfoMoved(t *testing.T) {
+	gh, err := github.New(
+		github.WithLogger(logger.NewTestLogger(t)),
+		github.WithHTTPClient(&http.Client{Transport: redirectingTransport{}}),
+	)
+	if err != nil {
+		t.Fatalf("❌ Failed to initialize Github client (%v)", err)
+	}
+	ctx := context.Background()
+
+	info, err := gh.GetRepositoryInfo(ctx, vcs.RepositoryAddr{Org: "old-org", Name: "terraform-aws-old"})
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+	}
+	if info.MovedTo == nil || info.MovedTo.String() != "new-org/terraform-aws-new" {
+		t.Fatalf("❌ The repository move was not detected: %v", info.MovedTo)
+	}
+
+	info, err = gh.GetRepositoryInfo(ctx, vcs.RepositoryAddr{Org: "New-Org", Name: "terraform-aws-new"})
+	if err != nil {
+		t.Fatalf("❌ Failed to fetch repository info (%v)", err)
+	}
+	if info.MovedTo != nil {
+		t.Fatalf("❌ A difference in case was reported as a move to %s.", info.MovedTo)
+	}
+}
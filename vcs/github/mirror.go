@@ -0,0 +1,431 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opentofu/libregistry/internal/retry"
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// directoryLock is a reference-counted lock for a single directory. The reference count makes sure that the lock is
+// only removed from the lock map when nobody is waiting for it anymore. The lock is held while the channel contains an
+// element, which lets waiters give up when their context is cancelled.
+type directoryLock struct {
+	held chan struct{}
+	refs int
+}
+
+// lockDirectory places an exclusive, in-process lock on the specified directory and returns the function to release
+// the lock. It returns an error if the context is cancelled before the lock could be acquired.
+func (g github) lockDirectory(ctx context.Context, directory string) (func(), error) {
+	g.lock.Lock()
+	lock := g.directoryLock(directory)
+	lock.refs++
+	g.lock.Unlock()
+
+	select {
+	case lock.held <- struct{}{}:
+		return g.unlockDirectory(directory, lock), nil
+	case <-ctx.Done():
+		g.lock.Lock()
+		g.releaseDirectoryLock(directory, lock)
+		g.lock.Unlock()
+		return nil, fmt.Errorf("failed to lock directory %s (%w)", directory, ctx.Err())
+	}
+}
+
+// tryLockDirectory works like lockDirectory, but returns false instead of waiting if the directory is already locked.
+func (g github) tryLockDirectory(directory string) (func(), bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	lock := g.directoryLock(directory)
+	select {
+	case lock.held <- struct{}{}:
+		lock.refs++
+		return g.unlockDirectory(directory, lock), true
+	default:
+		if lock.refs == 0 {
+			delete(g.locks, directory)
+		}
+		return nil, false
+	}
+}
+
+// directoryLock returns the lock for the specified directory, creating it if needed. The caller must hold g.lock.
+func (g github) directoryLock(directory string) *directoryLock {
+	lock, ok := g.locks[directory]
+	if !ok {
+		lock = &directoryLock{
+			held: make(chan struct{}, 1),
+		}
+		g.locks[directory] = lock
+	}
+	return lock
+}
+
+// releaseDirectoryLock drops a reference to the lock and removes it from the lock map if it is unused. The caller must
+// hold g.lock.
+func (g github) releaseDirectoryLock(directory string, lock *directoryLock) {
+	lock.refs--
+	if lock.refs == 0 {
+		delete(g.locks, directory)
+	}
+}
+
+func (g github) unlockDirectory(directory string, lock *directoryLock) func() {
+	return func() {
+		<-lock.held
+
+		g.lock.Lock()
+		g.releaseDirectoryLock(directory, lock)
+		g.lock.Unlock()
+	}
+}
+
+// mirrorFetchInterval is the time during which the tags of a mirror are not fetched again. Tags missing from the
+// mirror are fetched regardless, so new tags show up immediately.
+const mirrorFetchInterval = time.Minute
+
+// mirror is a bare, partial (blobless) clone of a repository. Working copies are created as git worktrees from the
+// mirror, which allows for checking out several versions of the same repository at the same time. All operations on
+// the mirror require the lock on the mirror to be held.
+type mirror struct {
+	g          github
+	repository vcs.RepositoryAddr
+	dir        string
+	release    func()
+	// fetched indicates that the tags have been fetched while holding the current lock.
+	fetched bool
+}
+
+// lockMirror returns the mirror of the specified repository with the lock held. The mirror may not exist on disk yet.
+// The caller must call release() on the mirror when done.
+func (g github) lockMirror(ctx context.Context, repository vcs.RepositoryAddr) (*mirror, error) {
+	dir := path.Join(g.config.MirrorDirectory, string(repository.Org), repository.Name+".git")
+	release, err := g.lockDirectory(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &mirror{
+		g:          g,
+		repository: repository,
+		dir:        dir,
+		release:    release,
+	}, nil
+}
+
+// getMirror returns the mirror of the specified repository with the lock held, creating it if needed and fetching
+// the current tags unless they have been fetched recently. The caller must call release() on the mirror when done.
+func (g github) getMirror(ctx context.Context, repository vcs.RepositoryAddr) (*mirror, error) {
+	if err := repository.Validate(); err != nil {
+		return nil, err
+	}
+	m, err := g.lockMirror(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.update(ctx); err != nil {
+		m.release()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *mirror) update(ctx context.Context) error {
+	stat, err := os.Stat(path.Join(m.dir, "HEAD"))
+	if err != nil || stat.IsDir() {
+		if err := os.RemoveAll(m.dir); err != nil {
+			return fmt.Errorf("failed to remove broken mirror directory %s (%w)", m.dir, err)
+		}
+		parentDirectory := path.Dir(m.dir)
+		if err := os.MkdirAll(parentDirectory, 0700); err != nil {
+			return fmt.Errorf("failed to create mirror parent directory %s (%w)", parentDirectory, err)
+		}
+		if err := m.g.git(ctx, parentDirectory, nil, "clone", "--bare", "--filter=blob:none", m.g.cloneURL(m.repository), m.dir); err != nil {
+			// Clone failed, check if repository exists.
+			repoExists, e := m.g.repositoryExists(ctx, m.repository)
+			if e == nil && !repoExists {
+				return &vcs.RepositoryNotFoundError{RepositoryAddr: m.repository, Cause: err}
+			}
+			return err
+		}
+		m.markFetched()
+		return nil
+	}
+
+	m.g.lock.Lock()
+	lastFetched, ok := m.g.fetched[m.dir]
+	m.g.lock.Unlock()
+	if ok && time.Since(lastFetched) < mirrorFetchInterval {
+		return nil
+	}
+	return m.fetch(ctx)
+}
+
+// fetch fetches the current tags from the remote.
+func (m *mirror) fetch(ctx context.Context) error {
+	// Mirrors created by earlier versions of this library have the credentials stored in the remote URL.
+	if err := m.g.git(ctx, m.dir, nil, "remote", "set-url", "origin", m.g.cloneURL(m.repository)); err != nil {
+		return fmt.Errorf("failed to set the remote URL of mirror %s (%w)", m.dir, err)
+	}
+	if err := m.g.git(ctx, m.dir, nil, "fetch", "--tags", "--force", "--prune", "--prune-tags", "origin"); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 128 {
+			return &vcs.RepositoryNotFoundError{RepositoryAddr: m.repository, Cause: err}
+		}
+		return err
+	}
+	m.markFetched()
+	return nil
+}
+
+func (m *mirror) markFetched() {
+	m.fetched = true
+	m.g.lock.Lock()
+	m.g.fetched[m.dir] = time.Now()
+	m.g.lock.Unlock()
+}
+
+// addWorktree creates a worktree in the specified directory without checking out any files, or reuses an existing
+// one. The caller must hold the lock on the mirror, but can release it before calling checkoutWorktree. The returned
+// boolean indicates if an existing worktree was reused.
+func (m *mirror) addWorktree(ctx context.Context, dir string, version vcs.VersionNumber) (bool, error) {
+	if err := removeLegacyCheckout(path.Dir(dir)); err != nil {
+		return false, err
+	}
+
+	// Worktrees have a .git file instead of a directory pointing to the mirror.
+	stat, err := os.Stat(path.Join(dir, ".git"))
+	if err == nil && !stat.IsDir() {
+		return true, nil
+	}
+	if err := m.removeWorktree(ctx, dir); err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(path.Dir(dir), 0700); err != nil {
+		return false, fmt.Errorf("failed to create worktree parent directory %s (%w)", path.Dir(dir), err)
+	}
+	if err := m.g.git(ctx, m.dir, nil, "worktree", "add", "--detach", "--no-checkout", dir, "refs/tags/"+string(version)); err != nil {
+		return false, fmt.Errorf("failed to create worktree for %s (%w)", version, err)
+	}
+	return false, nil
+}
+
+// removeLegacyCheckout removes the plain clone earlier versions of this library created in the repository directory,
+// which now holds one worktree per version. Worktrees have a .git file, so a .git directory means a plain clone.
+func removeLegacyCheckout(repositoryDir string) error {
+	stat, err := os.Stat(path.Join(repositoryDir, ".git"))
+	if err != nil || !stat.IsDir() {
+		return nil
+	}
+	if err := os.RemoveAll(repositoryDir); err != nil {
+		return fmt.Errorf("failed to remove legacy checkout %s (%w)", repositoryDir, err)
+	}
+	return nil
+}
+
+// checkoutWorktree checks out the given version in a worktree created by addWorktree. If directories are passed, the
+// worktree is limited to these directories using a sparse checkout. The checkout fetches the missing file contents
+// from the remote, so it does not need the lock on the mirror.
+func (g github) checkoutWorktree(ctx context.Context, dir string, version vcs.VersionNumber, directories []string, reused bool) error {
+	tagRef := "refs/tags/" + string(version)
+
+	if len(directories) > 0 {
+		if err := g.git(ctx, dir, nil, append([]string{"sparse-checkout", "set", "--"}, directories...)...); err != nil {
+			return fmt.Errorf("failed to configure sparse checkout (%w)", err)
+		}
+	} else if reused {
+		if err := g.git(ctx, dir, nil, "sparse-checkout", "disable"); err != nil {
+			return fmt.Errorf("failed to disable sparse checkout (%w)", err)
+		}
+	}
+
+	if err := retry.Func(
+		ctx,
+		"git checkout "+string(version),
+		func() error {
+			return g.git(ctx, dir, nil, "checkout", "--force", "--detach", tagRef)
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		g.config.Logger,
+	); err != nil {
+		return err
+	}
+
+	return retry.Func(
+		ctx,
+		"git clean -ffd",
+		func() error {
+			return g.git(ctx, dir, nil, "clean", "-ffd")
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		g.config.Logger,
+	)
+}
+
+// removeWorktree locks the mirror of the repository and removes the worktree in the specified directory.
+func (g github) removeWorktree(ctx context.Context, repository vcs.RepositoryAddr, dir string) error {
+	m, err := g.lockMirror(ctx, repository)
+	if err != nil {
+		return err
+	}
+	defer m.release()
+	return m.removeWorktree(ctx, dir)
+}
+
+// removeWorktree removes the worktree directory and prunes its administrative files from the mirror.
+func (m *mirror) removeWorktree(ctx context.Context, dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove worktree directory %s (%w)", dir, err)
+	}
+	// Remove the repository directory if this was the last worktree. This fails if the directory is not empty, which
+	// is fine.
+	_ = os.Remove(path.Dir(dir))
+
+	if _, err := os.Stat(path.Join(m.dir, "HEAD")); err != nil {
+		// No mirror, nothing to prune.
+		return nil
+	}
+	if err := m.g.git(ctx, m.dir, nil, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees in %s (%w)", m.dir, err)
+	}
+	return nil
+}
+
+func (m *mirror) tagExists(ctx context.Context, version vcs.VersionNumber) (bool, error) {
+	_, found, err := m.findTag(ctx, version)
+	return found, err
+}
+
+func (m *mirror) getTag(ctx context.Context, tag vcs.VersionNumber) (vcs.Version, error) {
+	t, found, err := m.findTag(ctx, tag)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	if !found {
+		return vcs.Version{}, &vcs.VersionNotFoundError{
+			RepositoryAddr: m.repository,
+			Version:        tag,
+		}
+	}
+	return t, nil
+}
+
+// findTag looks up the specified tag in the mirror. If the tag is missing and the tags have not been fetched while
+// holding the current lock, it fetches the tags and looks again.
+func (m *mirror) findTag(ctx context.Context, tag vcs.VersionNumber) (vcs.Version, bool, error) {
+	for {
+		tags, err := m.listTags(ctx)
+		if err != nil {
+			return vcs.Version{}, false, err
+		}
+		for _, t := range tags {
+			if t.VersionNumber.Equals(tag) {
+				return t, true, nil
+			}
+		}
+		if m.fetched {
+			return vcs.Version{}, false, nil
+		}
+		if err := m.fetch(ctx); err != nil {
+			return vcs.Version{}, false, err
+		}
+	}
+}
+
+func (m *mirror) listTags(ctx context.Context) ([]vcs.Version, error) {
+	stdout, err := m.listRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(stdout.String(), "\n")
+	var result []vcs.Version
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("line does not contain enough parts to parse: %s", line)
+		}
+		tag := vcs.VersionNumber(strings.TrimPrefix(parts[0], "refs/tags/"))
+		unixTime, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse git output: %s (%v)", line, err)
+		}
+		created := time.Unix(int64(unixTime), 0)
+		// Annotated tags point to a tag object, the peeled object name is the commit in this case.
+		commit := parts[2]
+		if commit == "" {
+			commit = parts[3]
+		}
+		ver := vcs.Version{
+			VersionNumber: tag,
+			Created:       created,
+			Commit:        commit,
+		}
+		if err := ver.Validate(); err != nil {
+			m.g.config.Logger.Debug(ctx, "Skipping tag %s because it does not match the naming rules.", ver.VersionNumber)
+			continue
+		}
+		result = append(result, ver)
+	}
+	return result, nil
+}
+
+func (m *mirror) listRefs(ctx context.Context) (*bytes.Buffer, error) {
+	return retry.Func2(
+		ctx,
+		"git for-each-ref",
+		func() (*bytes.Buffer, error) {
+			stdout := &bytes.Buffer{}
+			err := m.g.git(ctx, m.dir, stdout, "for-each-ref", "--format=%(refname)\t%(creatordate:format:%s)\t%(*objectname)\t%(objectname)", "refs/tags")
+			return stdout, err
+		},
+		is128Retryable,
+		10,
+		100*time.Millisecond,
+		m.g.config.Logger,
+	)
+}
+
+// cloneURL returns the URL of the repository without credentials, as git stores it in the mirror. See gitEnv for how
+// the credentials are passed.
+func (g github) cloneURL(repository vcs.RepositoryAddr) string {
+	return "https://github.com/" + url.PathEscape(string(repository.Org)) + "/" + url.PathEscape(repository.Name) + ".git"
+}
+
+// gitEnv returns the environment for git commands. The credentials are passed as an HTTP header for github.com using
+// environment variables, so they are neither stored in the mirror nor visible in the command line.
+func (g github) gitEnv() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	if g.config.Username != "" && g.config.Token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(g.config.Username + ":" + g.config.Token))
+		env = append(
+			env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	return env
+}
@@ -0,0 +1,196 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github
+
+import (
+	"container/list"
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sync"
+
+	"github.com/opentofu/libregistry/vcs"
+	"golang.org/x/sync/semaphore"
+)
+
+// workingCopyPool limits how many working copies can be checked out at the same time, globally and per repository,
+// and keeps track of the working copies retained on disk when SkipCleanupWorkingCopyOnClose is set.
+type workingCopyPool struct {
+	config Config
+
+	global *semaphore.Weighted
+
+	lock          *sync.Mutex
+	perRepository map[vcs.RepositoryAddr]*semaphore.Weighted
+	// retained holds *retainedWorkingCopy items, the front being the most recently used one.
+	retained      *list.List
+	retainedIndex map[string]*list.Element
+	retainedSize  int64
+}
+
+type retainedWorkingCopy struct {
+	repository vcs.RepositoryAddr
+	dir        string
+	size       int64
+}
+
+func newWorkingCopyPool(config Config) *workingCopyPool {
+	var global *semaphore.Weighted
+	if config.MaxWorkingCopies > 0 {
+		global = semaphore.NewWeighted(int64(config.MaxWorkingCopies))
+	}
+	return &workingCopyPool{
+		config:        config,
+		global:        global,
+		lock:          &sync.Mutex{},
+		perRepository: map[vcs.RepositoryAddr]*semaphore.Weighted{},
+		retained:      list.New(),
+		retainedIndex: map[string]*list.Element{},
+	}
+}
+
+// acquire waits until a working copy for the specified repository may be checked out and returns the function to
+// give the slot back.
+func (p *workingCopyPool) acquire(ctx context.Context, repository vcs.RepositoryAddr) (func(), error) {
+	var repositorySemaphore *semaphore.Weighted
+	if p.config.MaxWorkingCopiesPerRepository > 0 {
+		p.lock.Lock()
+		var ok bool
+		repositorySemaphore, ok = p.perRepository[repository]
+		if !ok {
+			repositorySemaphore = semaphore.NewWeighted(int64(p.config.MaxWorkingCopiesPerRepository))
+			p.perRepository[repository] = repositorySemaphore
+		}
+		p.lock.Unlock()
+
+		if err := repositorySemaphore.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+	}
+	if p.global != nil {
+		if err := p.global.Acquire(ctx, 1); err != nil {
+			if repositorySemaphore != nil {
+				repositorySemaphore.Release(1)
+			}
+			return nil, err
+		}
+	}
+	return func() {
+		if p.global != nil {
+			p.global.Release(1)
+		}
+		if repositorySemaphore != nil {
+			repositorySemaphore.Release(1)
+		}
+	}, nil
+}
+
+// take removes a retained working copy from the pool because it is being reused. The caller must hold the lock on
+// the directory.
+func (p *workingCopyPool) take(dir string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.remove(dir)
+}
+
+// retain records a closed working copy as retained on disk. The caller must hold the lock on the directory.
+func (p *workingCopyPool) retain(repository vcs.RepositoryAddr, dir string) {
+	var size int64
+	if p.config.MaxWorkingCopyDiskUsage > 0 {
+		size = directorySize(dir)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.remove(dir)
+	p.retainedIndex[dir] = p.retained.PushFront(&retainedWorkingCopy{
+		repository: repository,
+		dir:        dir,
+		size:       size,
+	})
+	p.retainedSize += size
+}
+
+// evictionCandidates returns the least recently used retained working copies that need to be removed in order to
+// stay within the configured limits.
+func (p *workingCopyPool) evictionCandidates() []*retainedWorkingCopy {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	count := p.retained.Len()
+	size := p.retainedSize
+	var result []*retainedWorkingCopy
+	for e := p.retained.Back(); e != nil; e = e.Prev() {
+		countExceeded := p.config.MaxRetainedWorkingCopies > 0 && count > p.config.MaxRetainedWorkingCopies
+		sizeExceeded := p.config.MaxWorkingCopyDiskUsage > 0 && size > p.config.MaxWorkingCopyDiskUsage
+		if !countExceeded && !sizeExceeded {
+			break
+		}
+		item := e.Value.(*retainedWorkingCopy)
+		result = append(result, item)
+		count--
+		size -= item.size
+	}
+	return result
+}
+
+// evict removes a retained working copy from the pool. It returns false if the working copy is no longer retained,
+// for example because it has been reused in the meantime. The caller must hold the lock on the directory.
+func (p *workingCopyPool) evict(dir string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.remove(dir)
+}
+
+func (p *workingCopyPool) remove(dir string) bool {
+	e, ok := p.retainedIndex[dir]
+	if !ok {
+		return false
+	}
+	p.retainedSize -= e.Value.(*retainedWorkingCopy).size
+	p.retained.Remove(e)
+	delete(p.retainedIndex, dir)
+	return true
+}
+
+// evictRetainedWorkingCopies removes the least recently used retained working copies from the disk until the pool is
+// within its configured limits again. Working copies that are currently in use are skipped.
+func (g github) evictRetainedWorkingCopies(ctx context.Context) {
+	for _, candidate := range g.pool.evictionCandidates() {
+		unlock, ok := g.tryLockDirectory(candidate.dir)
+		if !ok {
+			continue
+		}
+		if g.pool.evict(candidate.dir) {
+			m, err := g.lockMirror(ctx, candidate.repository)
+			if err != nil {
+				g.config.Logger.Debug(ctx, "Failed to evict retained worktree at %s (%v)", candidate.dir, err)
+				unlock()
+				continue
+			}
+			if err := m.removeWorktree(ctx, candidate.dir); err != nil {
+				g.config.Logger.Debug(ctx, "Failed to evict retained worktree at %s (%v)", candidate.dir, err)
+			}
+			m.release()
+		}
+		unlock()
+	}
+}
+
+func directorySize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Ignore files we cannot read, this is only an estimate.
+			return nil
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,77 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package github_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opentofu/libregistry/logger"
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/github"
+)
+
+// TestCheckoutPoolLimit tests that a checkout waits for a free slot when the per-repository limit of working copies
+// is reached. The checkouts use different versions, because checkouts of the same version also wait for each other's
+// working copy directory.
+func TestCheckoutPoolLimit(t *testing.T) {
+	t.Parallel()
+
+	const testOrg = "opentofu"
+	const testRepo = "terraform-provider-tfcoremock"
+	const testVersion = "v0.3.0"
+	const otherTestVersion = "v0.2.0"
+
+	gh, err := github.New(
+		github.WithCheckoutRootDirectory(t.TempDir()),
+		github.WithLogger(logger.NewTestLogger(t)),
+		github.WithToken(os.Getenv("GITHUB_TOKEN")),
+		github.WithMaxWorkingCopiesPerRepository(1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	repo := vcs.RepositoryAddr{
+		Org:  testOrg,
+		Name: testRepo,
+	}
+
+	workingCopy, err := gh.Checkout(ctx, repo, testVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if secondWorkingCopy, err := gh.Checkout(timeoutCtx, repo, otherTestVersion); err == nil {
+		_ = secondWorkingCopy.Close()
+		_ = workingCopy.Close()
+		t.Fatalf("❌ The second checkout did not wait for the first working copy to be closed.")
+	}
+
+	// A checkout of the same version waits for the directory and must give up when its context is cancelled.
+	timeoutCtx, cancel = context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if secondWorkingCopy, err := gh.Checkout(timeoutCtx, repo, testVersion); err == nil {
+		_ = secondWorkingCopy.Close()
+		_ = workingCopy.Close()
+		t.Fatalf("❌ The checkout of the same version did not wait for the first working copy to be closed.")
+	}
+
+	if err := workingCopy.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cancelled checkouts must not hold on to a slot.
+	workingCopy, err = gh.Checkout(ctx, repo, otherTestVersion)
+	if err != nil {
+		t.Fatalf("❌ Checkout failed after the first working copy was closed (%v)", err)
+	}
+	if err := workingCopy.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
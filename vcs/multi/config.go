@@ -0,0 +1,81 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package multi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// Opt is a function that modifies the config.
+type Opt func(config *Config) error
+
+// Config holds the configuration for the multi-VCS client.
+type Config struct {
+	// Backends holds the VCS clients by host, e.g. github.com or gitlab.example.com.
+	Backends map[string]vcs.Client
+	// DefaultHost is the host of the backend used for repository addresses without a host and for HasPermission
+	// calls. Defaults to no default host, which means that all repository addresses must have a host.
+	DefaultHost string
+	// DefaultBackend is used for repository addresses without a host and for HasPermission calls if the default
+	// backend is not reachable by a host, for example because its host is implied. It cannot be combined with
+	// DefaultHost.
+	DefaultBackend vcs.Client
+}
+
+// ApplyDefaults adds the default values if none are present.
+func (c *Config) ApplyDefaults() {
+	if c.Backends == nil {
+		c.Backends = map[string]vcs.Client{}
+	}
+}
+
+// Validate checks if the configuration is consistent.
+func (c Config) Validate() error {
+	for host := range c.Backends {
+		if err := (vcs.RepositoryAddr{Host: host, Org: "org", Name: "repo"}).Validate(); err != nil {
+			return fmt.Errorf("invalid backend host: %s", host)
+		}
+	}
+	if c.DefaultHost != "" {
+		if c.DefaultBackend != nil {
+			return fmt.Errorf("the default host and the default backend cannot both be configured")
+		}
+		if _, ok := c.Backends[c.DefaultHost]; !ok {
+			return fmt.Errorf("no backend configured for the default host %s", c.DefaultHost)
+		}
+	}
+	return nil
+}
+
+// WithBackend routes all repositories on the specified host to the VCS client.
+func WithBackend(host string, client vcs.Client) Opt {
+	return func(config *Config) error {
+		if config.Backends == nil {
+			config.Backends = map[string]vcs.Client{}
+		}
+		config.Backends[strings.ToLower(host)] = client
+		return nil
+	}
+}
+
+// WithDefaultHost sets the host used for repository addresses without a host. The host must also be configured
+// using WithBackend.
+func WithDefaultHost(host string) Opt {
+	return func(config *Config) error {
+		config.DefaultHost = strings.ToLower(host)
+		return nil
+	}
+}
+
+// WithDefaultBackend sets the VCS client used for repository addresses without a host. Unlike WithDefaultHost, the
+// client is not reachable using a host prefix.
+func WithDefaultBackend(client vcs.Client) Opt {
+	return func(config *Config) error {
+		config.DefaultBackend = client
+		return nil
+	}
+}
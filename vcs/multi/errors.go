@@ -0,0 +1,16 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package multi
+
+// UnknownHostError indicates that no backend is configured for the host of a repository.
+type UnknownHostError struct {
+	Host string
+}
+
+func (u UnknownHostError) Error() string {
+	if u.Host == "" {
+		return "No host specified and no default host configured"
+	}
+	return "No VCS backend configured for host: " + u.Host
+}
@@ -0,0 +1,259 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+// Package multi provides a VCS client that routes each call to one of several VCS clients based on the host of the
+// repository address. This allows a single registry to fetch repositories from GitHub, GitLab and other git servers
+// at the same time.
+package multi
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+// New creates a new multi-VCS client. Configure at least one backend using WithBackend. Repository addresses passed
+// to the backends never have a host, so the backends do not need to be aware of the routing.
+func New(opts ...Opt) (vcs.Client, error) {
+	config := Config{}
+	for _, opt := range opts {
+		if err := opt(&config); err != nil {
+			return nil, err
+		}
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &client{
+		config: config,
+	}, nil
+}
+
+type client struct {
+	config Config
+}
+
+// route returns the backend responsible for the repository and the repository address without the host.
+func (c *client) route(repository vcs.RepositoryAddr) (vcs.Client, vcs.RepositoryAddr, error) {
+	host := strings.ToLower(repository.Host)
+	if host == "" {
+		backend, ok := c.defaultBackend()
+		if !ok {
+			return nil, vcs.RepositoryAddr{}, &UnknownHostError{}
+		}
+		return backend, repository, nil
+	}
+	backend, ok := c.config.Backends[host]
+	if !ok {
+		return nil, vcs.RepositoryAddr{}, &UnknownHostError{Host: host}
+	}
+	repository.Host = ""
+	return backend, repository, nil
+}
+
+// defaultBackend returns the backend for repository addresses without a host.
+func (c *client) defaultBackend() (vcs.Client, bool) {
+	if c.config.DefaultBackend != nil {
+		return c.config.DefaultBackend, true
+	}
+	backend, ok := c.config.Backends[c.config.DefaultHost]
+	return backend, ok
+}
+
+// qualify adds the host to a repository address returned by a backend. Repositories on the default host are returned
+// without a host so they stay compatible with addresses created without this client.
+func (c *client) qualify(host string, repository vcs.RepositoryAddr) vcs.RepositoryAddr {
+	host = strings.ToLower(host)
+	if host == c.config.DefaultHost {
+		host = ""
+	}
+	repository.Host = host
+	return repository
+}
+
+// ParseRepositoryAddr parses a repository address in the form of host/org/repo or, if a default host is
+// configured, org/repo.
+func (c *client) ParseRepositoryAddr(ref string) (vcs.RepositoryAddr, error) {
+	host, rest, ok := strings.Cut(ref, "/")
+	if ok && strings.Contains(rest, "/") {
+		if backend, ok := c.config.Backends[strings.ToLower(host)]; ok {
+			repository, err := backend.ParseRepositoryAddr(rest)
+			if err != nil {
+				return vcs.RepositoryAddr{}, err
+			}
+			repository = c.qualify(host, repository)
+			return repository, repository.Validate()
+		}
+	}
+	backend, ok := c.defaultBackend()
+	if !ok {
+		unknownHost := ""
+		if strings.Contains(rest, "/") {
+			unknownHost = host
+		}
+		return vcs.RepositoryAddr{}, &vcs.InvalidRepositoryAddrError{
+			RepositoryString: ref,
+			Cause:            &UnknownHostError{Host: unknownHost},
+		}
+	}
+	return backend.ParseRepositoryAddr(ref)
+}
+
+func (c *client) GetRepositoryInfo(ctx context.Context, repository vcs.RepositoryAddr) (vcs.RepositoryInfo, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return vcs.RepositoryInfo{}, err
+	}
+	info, err := backend.GetRepositoryInfo(ctx, backendRepository)
+	if err != nil {
+		return vcs.RepositoryInfo{}, err
+	}
+	if info.ForkOf != nil && info.ForkOf.Host == "" {
+		forkOf := c.qualify(repository.Host, *info.ForkOf)
+		info.ForkOf = &forkOf
+	}
+	if info.MovedTo != nil && info.MovedTo.Host == "" {
+		movedTo := c.qualify(repository.Host, *info.MovedTo)
+		info.MovedTo = &movedTo
+	}
+	return info, nil
+}
+
+func (c *client) ListLatestTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListLatestTags(ctx, backendRepository)
+}
+
+func (c *client) ListAllTags(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAllTags(ctx, backendRepository)
+}
+
+func (c *client) GetTagVersion(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.Version, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return vcs.Version{}, err
+	}
+	return backend.GetTagVersion(ctx, backendRepository, version)
+}
+
+func (c *client) ListLatestReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListLatestReleases(ctx, backendRepository)
+}
+
+func (c *client) ListAllReleases(ctx context.Context, repository vcs.RepositoryAddr) ([]vcs.Version, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAllReleases(ctx, backendRepository)
+}
+
+func (c *client) ListAssets(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) ([]vcs.AssetName, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ListAssets(ctx, backendRepository, version)
+}
+
+func (c *client) DownloadAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) ([]byte, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	return backend.DownloadAsset(ctx, backendRepository, version, asset)
+}
+
+func (c *client) OpenAsset(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, asset vcs.AssetName) (io.ReadCloser, vcs.AssetInfo, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, vcs.AssetInfo{}, err
+	}
+	return backend.OpenAsset(ctx, backendRepository, version, asset)
+}
+
+// HasPermission checks the permission using the default backend, because organization addresses do not carry a host.
+func (c *client) HasPermission(ctx context.Context, username vcs.Username, organization vcs.OrganizationAddr) (bool, error) {
+	backend, ok := c.defaultBackend()
+	if !ok {
+		return false, &UnknownHostError{}
+	}
+	return backend.HasPermission(ctx, username, organization)
+}
+
+func (c *client) Checkout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (vcs.WorkingCopy, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	workingCopy, err := backend.Checkout(ctx, backendRepository, version)
+	if err != nil {
+		return nil, err
+	}
+	return &routedWorkingCopy{workingCopy, c, repository}, nil
+}
+
+func (c *client) SparseCheckout(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, directories ...string) (vcs.WorkingCopy, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return nil, err
+	}
+	workingCopy, err := backend.SparseCheckout(ctx, backendRepository, version, directories...)
+	if err != nil {
+		return nil, err
+	}
+	return &routedWorkingCopy{workingCopy, c, repository}, nil
+}
+
+func (c *client) GetRepositoryBrowseURL(ctx context.Context, repository vcs.RepositoryAddr) (string, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return "", err
+	}
+	return backend.GetRepositoryBrowseURL(ctx, backendRepository)
+}
+
+func (c *client) GetVersionBrowseURL(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber) (string, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return "", err
+	}
+	return backend.GetVersionBrowseURL(ctx, backendRepository, version)
+}
+
+func (c *client) GetFileViewURL(ctx context.Context, repository vcs.RepositoryAddr, version vcs.VersionNumber, file string) (string, error) {
+	backend, backendRepository, err := c.route(repository)
+	if err != nil {
+		return "", err
+	}
+	return backend.GetFileViewURL(ctx, backendRepository, version, file)
+}
+
+// routedWorkingCopy reports the multi client and the original repository address instead of the ones of the backend.
+type routedWorkingCopy struct {
+	vcs.WorkingCopy
+	client     *client
+	repository vcs.RepositoryAddr
+}
+
+func (r *routedWorkingCopy) Client() vcs.Client {
+	return r.client
+}
+
+func (r *routedWorkingCopy) Repository() vcs.RepositoryAddr {
+	return r.repository
+}
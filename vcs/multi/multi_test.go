@@ -0,0 +1,184 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package multi_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/opentofu/libregistry/vcs"
+	"github.com/opentofu/libregistry/vcs/fakevcs"
+	"github.com/opentofu/libregistry/vcs/multi"
+)
+
+func TestRouting(t *testing.T) {
+	ctx := context.Background()
+	githubVCS := fakevcs.New()
+	gitlabVCS := fakevcs.New()
+	createRepository(t, githubVCS, "v1.0.0")
+	createRepository(t, gitlabVCS, "v2.0.0")
+
+	client, err := multi.New(
+		multi.WithBackend("github.com", githubVCS),
+		multi.WithBackend("gitlab.example.com", gitlabVCS),
+		multi.WithDefaultHost("github.com"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client (%v)", err)
+	}
+
+	for ref, expected := range map[string]struct {
+		addr    vcs.RepositoryAddr
+		version vcs.VersionNumber
+	}{
+		"org/repo":                    {vcs.RepositoryAddr{Org: "org", Name: "repo"}, "v1.0.0"},
+		"github.com/org/repo":         {vcs.RepositoryAddr{Org: "org", Name: "repo"}, "v1.0.0"},
+		"gitlab.example.com/org/repo": {vcs.RepositoryAddr{Host: "gitlab.example.com", Org: "org", Name: "repo"}, "v2.0.0"},
+	} {
+		t.Run(ref, func(t *testing.T) {
+			addr, err := client.ParseRepositoryAddr(ref)
+			if err != nil {
+				t.Fatalf("Failed to parse repository address (%v)", err)
+			}
+			if addr != expected.addr {
+				t.Fatalf("Incorrect repository address: %v (expected: %v)", addr, expected.addr)
+			}
+			tags, err := client.ListAllTags(ctx, addr)
+			if err != nil {
+				t.Fatalf("Failed to list tags (%v)", err)
+			}
+			if len(tags) != 1 || tags[0].VersionNumber != expected.version {
+				t.Fatalf("The call was routed to the wrong backend: %v", tags)
+			}
+			workingCopy, err := client.Checkout(ctx, addr, expected.version)
+			if err != nil {
+				t.Fatalf("Failed to check out repository (%v)", err)
+			}
+			defer func() {
+				_ = workingCopy.Close()
+			}()
+			if workingCopy.Repository() != addr {
+				t.Fatalf("Incorrect working copy repository: %v", workingCopy.Repository())
+			}
+			if workingCopy.Client() != client {
+				t.Fatalf("The working copy does not report the multi client.")
+			}
+		})
+	}
+}
+
+func TestDefaultBackend(t *testing.T) {
+	ctx := context.Background()
+	defaultVCS := fakevcs.New()
+	gitlabVCS := fakevcs.New()
+	createRepository(t, defaultVCS, "v1.0.0")
+	createRepository(t, gitlabVCS, "v2.0.0")
+
+	client, err := multi.New(
+		multi.WithDefaultBackend(defaultVCS),
+		multi.WithBackend("gitlab.example.com", gitlabVCS),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client (%v)", err)
+	}
+
+	for ref, expected := range map[string]vcs.VersionNumber{
+		"org/repo":                    "v1.0.0",
+		"gitlab.example.com/org/repo": "v2.0.0",
+	} {
+		addr, err := client.ParseRepositoryAddr(ref)
+		if err != nil {
+			t.Fatalf("Failed to parse repository address %s (%v)", ref, err)
+		}
+		if addr.String() != ref {
+			t.Fatalf("Incorrect repository address: %s (expected: %s)", addr, ref)
+		}
+		tags, err := client.ListAllTags(ctx, addr)
+		if err != nil {
+			t.Fatalf("Failed to list tags (%v)", err)
+		}
+		if len(tags) != 1 || tags[0].VersionNumber != expected {
+			t.Fatalf("The call for %s was routed to the wrong backend: %v", ref, tags)
+		}
+	}
+
+	if _, err := multi.New(
+		multi.WithDefaultBackend(defaultVCS),
+		multi.WithBackend("github.com", fakevcs.New()),
+		multi.WithDefaultHost("github.com"),
+	); err == nil {
+		t.Fatalf("Creating a client with both a default host and a default backend did not fail.")
+	}
+}
+
+func TestMovedRepository(t *testing.T) {
+	ctx := context.Background()
+	githubVCS := fakevcs.New()
+	gitlabVCS := fakevcs.New()
+	createRepository(t, githubVCS, "v1.0.0")
+	createRepository(t, gitlabVCS, "v2.0.0")
+	for _, backend := range []fakevcs.VCSClient{githubVCS, gitlabVCS} {
+		if err := backend.MoveRepository(vcs.RepositoryAddr{Org: "org", Name: "repo"}, vcs.RepositoryAddr{Org: "org", Name: "renamed"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client, err := multi.New(
+		multi.WithBackend("github.com", githubVCS),
+		multi.WithBackend("gitlab.example.com", gitlabVCS),
+		multi.WithDefaultHost("github.com"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client (%v)", err)
+	}
+
+	for _, host := range []string{"", "gitlab.example.com"} {
+		info, err := client.GetRepositoryInfo(ctx, vcs.RepositoryAddr{Host: host, Org: "org", Name: "repo"})
+		if err != nil {
+			t.Fatalf("Failed to get repository info (%v)", err)
+		}
+		expected := vcs.RepositoryAddr{Host: host, Org: "org", Name: "renamed"}
+		if info.MovedTo == nil || *info.MovedTo != expected {
+			t.Fatalf("Incorrect new address: %v (expected: %v)", info.MovedTo, expected)
+		}
+	}
+}
+
+func TestUnknownHost(t *testing.T) {
+	client, err := multi.New(multi.WithBackend("github.com", fakevcs.New()))
+	if err != nil {
+		t.Fatalf("Failed to create client (%v)", err)
+	}
+	if _, err := client.ParseRepositoryAddr("gitlab.example.com/org/repo"); err == nil {
+		t.Fatalf("Parsing a repository on an unknown host did not fail.")
+	}
+	_, err = client.ListAllTags(context.Background(), vcs.RepositoryAddr{Org: "org", Name: "repo"})
+	var unknownHost *multi.UnknownHostError
+	if !errors.As(err, &unknownHost) {
+		t.Fatalf("Incorrect error for a repository without host and no default host: %v", err)
+	}
+}
+
+func TestInvalidDefaultHost(t *testing.T) {
+	if _, err := multi.New(multi.WithDefaultHost("github.com")); err == nil {
+		t.Fatalf("Creating a client with a default host without backend did not fail.")
+	}
+}
+
+func createRepository(t *testing.T, client fakevcs.VCSClient, version vcs.VersionNumber) {
+	t.Helper()
+	repository := vcs.RepositoryAddr{Org: "org", Name: "repo"}
+	if err := client.CreateOrganization(repository.Org); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateRepository(repository, vcs.RepositoryInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateVersion(repository, version, fs.ReadDirFS(fstest.MapFS{})); err != nil {
+		t.Fatal(err)
+	}
+}
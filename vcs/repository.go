@@ -11,20 +11,34 @@ import (
 // out that other VCS' support different address styles.
 var nameRe = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
 
+// hostRe describes an acceptable VCS host name with an optional port.
+var hostRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*(:[0-9]+)?$`)
+
 // RepositoryAddr holds a reference to a repository. For simplicity, the current system does not support more complex
 // URL structures.
 type RepositoryAddr struct {
-	Org OrganizationAddr
+	// Host is the optional VCS host the repository is located on, e.g. gitlab.example.com. It is empty if the
+	// repository is on the host of the VCS client it is used with.
+	Host string `json:",omitempty"`
+	Org  OrganizationAddr
 	// Name is the URL fragment of a repository.
 	Name string
 }
 
 func (r RepositoryAddr) String() string {
+	if r.Host != "" {
+		return r.Host + "/" + string(r.Org) + "/" + r.Name
+	}
 	return string(r.Org) + "/" + r.Name
 }
 
 // Validate checks the assumptions the registry makes about repositories.
 func (r RepositoryAddr) Validate() error {
+	if r.Host != "" && !hostRe.MatchString(r.Host) {
+		return &InvalidRepositoryAddrError{
+			RepositoryAddr: r,
+		}
+	}
 	if err := r.Org.Validate(); err != nil {
 		return &InvalidRepositoryAddrError{RepositoryAddr: r, Cause: err}
 	}
@@ -0,0 +1,26 @@
+// Copyright (c) The OpenTofu Authors
+// SPDX-License-Identifier: MPL-2.0
+
+package vcs_test
+
+import (
+	"testing"
+
+	"github.com/opentofu/libregistry/vcs"
+)
+
+func TestRepositoryAddrHost(t *testing.T) {
+	addr := vcs.RepositoryAddr{Host: "gitlab.example.com:8443", Org: "org", Name: "repo"}
+	if err := addr.Validate(); err != nil {
+		t.Fatalf("Failed to validate repository address (%v)", err)
+	}
+	if addr.String() != "gitlab.example.com:8443/org/repo" {
+		t.Fatalf("Incorrect string representation: %s", addr.String())
+	}
+	if (vcs.RepositoryAddr{Org: "org", Name: "repo"}).String() != "org/repo" {
+		t.Fatalf("Incorrect string representation without host.")
+	}
+	if err := (vcs.RepositoryAddr{Host: "gitlab/example", Org: "org", Name: "repo"}).Validate(); err == nil {
+		t.Fatalf("Invalid host did not fail validation.")
+	}
+}
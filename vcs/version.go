@@ -10,7 +10,7 @@ import (
 
 type VersionNumber string
 
-var versionRe = regexp.MustCompile("^[a-zA-Z0-9/._-]+$")
+var versionRe = regexp.MustCompile("^[a-zA-Z0-9/._+-]+$")
 
 const maxVersionLength = 255
 
@@ -36,6 +36,9 @@ func (v VersionNumber) Equals(tag VersionNumber) bool {
 type Version struct {
 	VersionNumber VersionNumber
 	Created       time.Time
+	// Commit is the commit SHA the version resolves to. This may be empty if the listing method used does not
+	// provide the commit.
+	Commit string
 }
 
 func (v Version) Validate() error {
@@ -49,6 +52,11 @@ func (v Version) Equals(other Version) bool {
 	return v.VersionNumber.Equals(other.VersionNumber) && v.Created == other.Created
 }
 
+// EqualsWithCommit works like Equals, but also compares the commit SHA.
+func (v Version) EqualsWithCommit(other Version) bool {
+	return v.Equals(other) && v.Commit == other.Commit
+}
+
 func (v Version) String() string {
 	return string(v.VersionNumber)
 }